@@ -15,6 +15,8 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/sinclairtarget/git-who/internal/git"
 )
@@ -43,6 +45,9 @@ type GobBackend struct {
 
 const GobBackendName string = "gob"
 
+// Maximum number of cache files to keep for a repo, one per repo state
+const maxGobCacheFiles = 4
+
 func (b *GobBackend) Name() string {
 	return GobBackendName
 }
@@ -76,26 +81,64 @@ func (b *GobBackend) Close() error {
 		return err
 	}
 
-	// Remove any other dangling cache files
-	matches, err := filepath.Glob(filepath.Join(b.Dir, "*"))
+	// Mark this cache file as the most recently used
+	now := time.Now()
+	err = os.Chtimes(b.compressedPath(), now, now)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	b.removeStale()
+	return nil
+}
+
+// Removes the least recently used cache files beyond the most recent
+// maxGobCacheFiles.
+//
+// Each different repo state (mailmap, ignore revs, diff options) gets its own
+// cache file. We keep a few of them around so that switching back and forth
+// between, say, runs with and without -w doesn't throw away the cache each
+// time.
+func (b *GobBackend) removeStale() {
+	matches, err := filepath.Glob(filepath.Join(b.Dir, "*.gz"))
 	if err != nil {
 		panic(err) // Bad pattern
 	}
 
+	type cacheFile struct {
+		path    string
+		modTime time.Time
+	}
+
+	files := []cacheFile{}
 	for _, match := range matches {
-		if match == b.compressedPath() {
+		info, err := os.Stat(match)
+		if err != nil {
 			continue
 		}
 
-		err := os.Remove(match)
-		if err != nil {
-			logger().Warn(
-				fmt.Sprintf("failed to delete old cache file: %v", err),
-			)
-		}
+		files = append(files, cacheFile{match, info.ModTime()})
 	}
 
-	return nil
+	slices.SortFunc(files, func(a, b cacheFile) int {
+		return b.modTime.Compare(a.modTime)
+	})
+
+	for i, f := range files {
+		if i < maxGobCacheFiles || f.path == b.compressedPath() {
+			continue
+		}
+
+		// Also remove the uncompressed file if one was left behind
+		for _, p := range []string{f.path, strings.TrimSuffix(f.path, ".gz")} {
+			err := os.RemoveAll(p)
+			if err != nil {
+				logger().Warn(
+					fmt.Sprintf("failed to delete old cache file: %v", err),
+				)
+			}
+		}
+	}
 }
 
 func (b *GobBackend) Get(revs []string) (iter.Seq[git.Commit], func() error) {
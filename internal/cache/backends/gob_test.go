@@ -193,3 +193,121 @@ func TestGobAddGetAddGet(t *testing.T) {
 		)
 	}
 }
+
+// SHA-256 repositories have 64-character commit hashes
+func TestGobAddGetSHA256(t *testing.T) {
+	dir := CacheDir(t)
+	c := backends.GobBackend{
+		Dir:  dir,
+		Path: filepath.Join(dir, "commits.gob"),
+	}
+
+	err := c.Open()
+	if err != nil {
+		t.Fatalf("could not open cache: %v", err)
+	}
+	defer func() {
+		err = c.Close()
+		if err != nil {
+			t.Fatalf("could not close cache: %v", err)
+		}
+	}()
+
+	commit := git.Commit{
+		ShortHash:      "8d2f1e0",
+		Hash:           "8d2f1e0b6a9c4d3e7f5a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f506",
+		AuthorName:     "Bob",
+		AuthorEmail:    "bob@work.com",
+		Date:           time.Date(2025, 1, 31, 16, 35, 26, 0, time.UTC),
+		AuthorOffset:   -5 * 60 * 60,
+		CommitterName:  "Alice",
+		CommitterEmail: "alice@work.com",
+		CommitterDate:  time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+		FileDiffs: []git.FileDiff{
+			{
+				Path:         "foo/bar.txt",
+				LinesAdded:   3,
+				LinesRemoved: 5,
+			},
+		},
+	}
+
+	err = c.Add([]git.Commit{commit})
+	if err != nil {
+		t.Fatalf("add commits to cache failed with error: %v", err)
+	}
+
+	it, finish := c.Get([]string{commit.Hash})
+	commits := slices.Collect(it)
+	err = finish()
+	if err != nil {
+		t.Fatalf("error iterating cached commits: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf(
+			"expected to get one commit from cache, but got %d",
+			len(commits),
+		)
+	}
+
+	if diff := cmp.Diff(commit, commits[0]); diff != "" {
+		t.Errorf("commit is wrong:\n%s", diff)
+	}
+}
+
+// Cache files for other repo states should survive, up to a limit
+func TestGobCloseKeepsRecentCacheFiles(t *testing.T) {
+	dir := CacheDir(t)
+
+	// Cache files left behind by earlier runs, oldest first
+	old := []string{"a.gobs.gz", "b.gobs.gz", "c.gobs.gz", "d.gobs.gz"}
+	for i, name := range old {
+		p := filepath.Join(dir, name)
+		err := os.WriteFile(p, []byte{}, 0644)
+		if err != nil {
+			t.Fatalf("could not create cache file: %v", err)
+		}
+
+		mtime := time.Date(2025, 1, i+1, 0, 0, 0, 0, time.UTC)
+		err = os.Chtimes(p, mtime, mtime)
+		if err != nil {
+			t.Fatalf("could not set cache file mtime: %v", err)
+		}
+	}
+
+	c := backends.GobBackend{
+		Dir:  dir,
+		Path: filepath.Join(dir, "e.gobs"),
+	}
+
+	err := c.Open()
+	if err != nil {
+		t.Fatalf("could not open cache: %v", err)
+	}
+
+	err = c.Add([]git.Commit{{Hash: "9e9ea7662b1001d860471a4cece5e2f1de8062fb"}})
+	if err != nil {
+		t.Fatalf("add commits to cache failed with error: %v", err)
+	}
+
+	err = c.Close()
+	if err != nil {
+		t.Fatalf("could not close cache: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("could not read cache dir: %v", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	expected := []string{"b.gobs.gz", "c.gobs.gz", "d.gobs.gz", "e.gobs.gz"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("cache files are wrong:\n%s", diff)
+	}
+}
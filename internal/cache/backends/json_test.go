@@ -180,3 +180,63 @@ func TestAddGetAddGet(t *testing.T) {
 		)
 	}
 }
+
+// SHA-256 repositories have 64-character commit hashes
+func TestAddGetSHA256(t *testing.T) {
+	dir := t.TempDir()
+	c := backends.JSONBackend{
+		Path: filepath.Join(dir, "commits.json"),
+	}
+
+	err := c.Open()
+	if err != nil {
+		t.Fatalf("could not open cache: %v", err)
+	}
+	defer func() {
+		err = c.Close()
+		if err != nil {
+			t.Fatalf("could not close cache: %v", err)
+		}
+	}()
+
+	commit := git.Commit{
+		ShortHash:      "8d2f1e0",
+		Hash:           "8d2f1e0b6a9c4d3e7f5a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f506",
+		AuthorName:     "Bob",
+		AuthorEmail:    "bob@work.com",
+		Date:           time.Date(2025, 1, 31, 16, 35, 26, 0, time.UTC),
+		CommitterName:  "Alice",
+		CommitterEmail: "alice@work.com",
+		CommitterDate:  time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+		FileDiffs: []git.FileDiff{
+			{
+				Path:         "foo/bar.txt",
+				LinesAdded:   3,
+				LinesRemoved: 5,
+			},
+		},
+	}
+
+	err = c.Add([]git.Commit{commit})
+	if err != nil {
+		t.Fatalf("add commits to cache failed with error: %v", err)
+	}
+
+	it, finish := c.Get([]string{commit.Hash})
+	commits := slices.Collect(it)
+	err = finish()
+	if err != nil {
+		t.Fatalf("error iterating cached commits: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf(
+			"expected to get one commit from cache, but got %d",
+			len(commits),
+		)
+	}
+
+	if diff := cmp.Diff(commit, commits[0]); diff != "" {
+		t.Errorf("commit is wrong:\n%s", diff)
+	}
+}
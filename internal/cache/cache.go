@@ -9,10 +9,12 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/sinclairtarget/git-who/internal/cache/backends"
 	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
 	"github.com/sinclairtarget/git-who/internal/git/config"
 )
 
@@ -175,14 +177,34 @@ func cacheStorageDir(name string) (_ string, err error) {
 	return absP, nil
 }
 
+// Version of the data we store for each commit. Bump this whenever fields are
+// added to git.Commit or git.FileDiff so that commits cached by an older
+// version of git who aren't read back missing those fields. Also bump it when
+// we change which commits get parsed, so that commits an older version skipped
+// aren't missing from the cache.
+const formatVersion = 6
+
 // Hash of all the state in the repo that affects the validity of our cache
-func repoStateHash(sf config.SupplementalFiles) (string, error) {
+func repoStateHash(
+	sf config.SupplementalFiles,
+	diffOpts cmd.DiffOpts,
+) (string, error) {
 	h := fnv.New32()
+	fmt.Fprintf(h, "v%d", formatVersion)
 	err := sf.MailmapHash(h)
 	if err != nil {
 		return "", err
 	}
 
+	err = sf.IgnoreRevsHash(h)
+	if err != nil {
+		return "", err
+	}
+
+	// Diffs computed with different options have different line counts, so
+	// must be cached separately
+	h.Write([]byte(strings.Join(diffOpts.ToArgs(), " ")))
+
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
@@ -194,7 +216,11 @@ func warnFail(cb Backend, err error) Cache {
 	return NewCache(cb)
 }
 
-func GetCache(gitRootPath string, configFiles config.SupplementalFiles) Cache {
+func GetCache(
+	gitRootPath string,
+	configFiles config.SupplementalFiles,
+	diffOpts cmd.DiffOpts,
+) Cache {
 	var fallback Backend = backends.NoopBackend{}
 
 	if !IsCachingEnabled() {
@@ -214,7 +240,7 @@ func GetCache(gitRootPath string, configFiles config.SupplementalFiles) Cache {
 		return warnFail(fallback, err)
 	}
 
-	stateHash, err := repoStateHash(configFiles)
+	stateHash, err := repoStateHash(configFiles, diffOpts)
 	if err != nil {
 		return warnFail(fallback, err)
 	}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+)
+
+// Diffs computed with different options must not share a cache file
+func TestRepoStateHashDiffOpts(t *testing.T) {
+	sf := config.SupplementalFiles{}
+
+	allOpts := []cmd.DiffOpts{
+		{},
+		{IgnoreSpace: true},
+		{IgnoreBlankLines: true},
+		{IgnoreSpace: true, IgnoreBlankLines: true},
+		{FirstParentMerges: true},
+	}
+
+	seen := map[string]cmd.DiffOpts{}
+	for _, opts := range allOpts {
+		h, err := repoStateHash(sf, opts)
+		if err != nil {
+			t.Fatalf("failed to hash repo state: %v", err)
+		}
+
+		if other, ok := seen[h]; ok {
+			t.Errorf("%+v and %+v have the same hash %s", opts, other, h)
+		}
+		seen[h] = opts
+
+		again, err := repoStateHash(sf, opts)
+		if err != nil {
+			t.Fatalf("failed to hash repo state: %v", err)
+		}
+
+		if again != h {
+			t.Errorf("hash for %+v is not stable: %s != %s", opts, h, again)
+		}
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/sinclairtarget/git-who/internal/git"
 	"github.com/sinclairtarget/git-who/internal/git/cmd"
 	"github.com/sinclairtarget/git-who/internal/git/config"
+	rev "github.com/sinclairtarget/git-who/internal/git/revision"
 	"github.com/sinclairtarget/git-who/internal/pretty"
 	"github.com/sinclairtarget/git-who/internal/tally"
 )
@@ -41,8 +42,13 @@ const chunkSize = 1024
 
 var nCPU int
 
+// Limits the number of git log processes run by workers at once across all
+// concurrent tallies.
+var gitSlots chan struct{}
+
 func init() {
 	nCPU = runtime.GOMAXPROCS(0)
+	gitSlots = make(chan struct{}, nCPU)
 }
 
 type tallyFunc[T any] func(
@@ -56,13 +62,31 @@ type combinable[T any] interface {
 
 // tally job we can do concurrently
 type whoperation[T combinable[T]] struct {
-	revspec    []string
-	pathspecs  []string
-	filters    cmd.LogFilters
-	useMailmap bool
-	ignoreRevs []string
-	tally      tallyFunc[T]
-	opts       tally.TallyOpts
+	revspec      []string
+	pathspecs    []string
+	filters      cmd.LogFilters
+	diffOpts     cmd.DiffOpts
+	useMailmap   bool
+	objectFormat rev.ObjectFormat
+	ignoreRevs   []string
+	tally        tallyFunc[T]
+	opts         tally.TallyOpts
+}
+
+// By-path tallies along with the renames seen while tallying them. Renames can
+// only be followed once the tallies for all chunks have been combined.
+type pathTalliesWithRenames struct {
+	byPath  tally.TalliesByPath
+	renames tally.Renames
+}
+
+func (a pathTalliesWithRenames) Combine(
+	b pathTalliesWithRenames,
+) pathTalliesWithRenames {
+	return pathTalliesWithRenames{
+		byPath:  a.byPath.Combine(b.byPath),
+		renames: a.renames.Combine(b.renames),
+	}
 }
 
 func calcTotalChunks(revCount int) int {
@@ -335,24 +359,27 @@ func TallyCommits(
 	revspec []string,
 	pathspecs []string,
 	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
 	configFiles config.SupplementalFiles,
 	opts tally.TallyOpts,
 	cache cache.Cache,
 	allowProgressBar bool,
 ) (_ map[string]tally.Tally, err error) {
-	ignoreRevs, err := configFiles.IgnoreRevs()
+	ignoreRevs, err := configFiles.IgnoreRevs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	whop := whoperation[tally.TalliesByPath]{
-		revspec:    revspec,
-		pathspecs:  pathspecs,
-		filters:    filters,
-		useMailmap: configFiles.HasMailmap(),
-		ignoreRevs: ignoreRevs,
-		tally:      tally.TallyCommitsByPath,
-		opts:       opts,
+		revspec:      revspec,
+		pathspecs:    pathspecs,
+		filters:      filters,
+		diffOpts:     diffOpts,
+		useMailmap:   configFiles.HasMailmap(),
+		objectFormat: configFiles.ObjectFormat,
+		ignoreRevs:   ignoreRevs,
+		tally:        tally.TallyCommitsByPath,
+		opts:         opts,
 	}
 
 	talliesByPath, err := tallyFanOutFanIn[tally.TalliesByPath](
@@ -368,34 +395,51 @@ func TallyCommits(
 	return talliesByPath.Reduce(), nil
 }
 
-func TallyCommitsTree(
+// Tallies commits by path, crediting edits to renamed files to their latest
+// path if opts.FollowRenames is set.
+func TallyCommitsByPath(
 	ctx context.Context,
 	revspec []string,
 	pathspecs []string,
 	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
 	configFiles config.SupplementalFiles,
 	opts tally.TallyOpts,
-	worktreePaths map[string]bool,
-	gitRootPath string,
 	cache cache.Cache,
 	allowProgressBar bool,
-) (*tally.TreeNode, error) {
-	ignoreRevs, err := configFiles.IgnoreRevs()
+) (tally.TalliesByPath, error) {
+	ignoreRevs, err := configFiles.IgnoreRevs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	whop := whoperation[tally.TalliesByPath]{
-		revspec:    revspec,
-		pathspecs:  pathspecs,
-		filters:    filters,
-		useMailmap: configFiles.HasMailmap(),
-		ignoreRevs: ignoreRevs,
-		tally:      tally.TallyCommitsByPath,
-		opts:       opts,
+	f := func(
+		commits iter.Seq[git.Commit],
+		opts tally.TallyOpts,
+	) (pathTalliesWithRenames, error) {
+		var result pathTalliesWithRenames
+		if opts.FollowRenames {
+			commits = tally.TeeRenames(commits, &result.renames)
+		}
+
+		byPath, err := tally.TallyCommitsByPath(commits, opts)
+		result.byPath = byPath
+		return result, err
 	}
 
-	talliesByPath, err := tallyFanOutFanIn[tally.TalliesByPath](
+	whop := whoperation[pathTalliesWithRenames]{
+		revspec:      revspec,
+		pathspecs:    pathspecs,
+		filters:      filters,
+		diffOpts:     diffOpts,
+		useMailmap:   configFiles.HasMailmap(),
+		objectFormat: configFiles.ObjectFormat,
+		ignoreRevs:   ignoreRevs,
+		tally:        f,
+		opts:         opts,
+	}
+
+	result, err := tallyFanOutFanIn[pathTalliesWithRenames](
 		ctx,
 		whop,
 		cache,
@@ -405,25 +449,64 @@ func TallyCommitsTree(
 		return nil, err
 	}
 
-	return tally.TallyCommitsTreeFromPaths(
-		talliesByPath,
-		worktreePaths,
-		gitRootPath,
+	renames := result.renames
+	if len(renames) > 0 {
+		// Chunks are tallied (or read from the cache) in no particular order
+		revs, err := git.RevList(ctx, revspec, pathspecs, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		renames = renames.InLogOrder(revs)
+	}
+
+	return result.byPath.FollowRenames(renames), nil
+}
+
+func TallyCommitsTree(
+	ctx context.Context,
+	revspec []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	configFiles config.SupplementalFiles,
+	opts tally.TallyOpts,
+	worktreePaths map[string]bool,
+	gitRootPath string,
+	cache cache.Cache,
+	allowProgressBar bool,
+) (*tally.TreeNode, error) {
+	byPath, err := TallyCommitsByPath(
+		ctx,
+		revspec,
+		pathspecs,
+		filters,
+		diffOpts,
+		configFiles,
+		opts,
+		cache,
+		allowProgressBar,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tally.TallyCommitsTreeFromPaths(byPath, worktreePaths, gitRootPath)
 }
 
-func TallyCommitsTimeline(
+// Tallies commits into daily time buckets.
+func TallyCommitsByDate(
 	ctx context.Context,
 	revspec []string,
 	pathspecs []string,
 	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
 	configFiles config.SupplementalFiles,
 	opts tally.TallyOpts,
-	end time.Time,
 	cache cache.Cache,
 	allowProgressBar bool,
-) ([]tally.TimeBucket, error) {
-	ignoreRevs, err := configFiles.IgnoreRevs()
+) (tally.TimeSeries, error) {
+	ignoreRevs, err := configFiles.IgnoreRevs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -436,29 +519,51 @@ func TallyCommitsTimeline(
 	}
 
 	whop := whoperation[tally.TimeSeries]{
-		revspec:    revspec,
-		pathspecs:  pathspecs,
-		filters:    filters,
-		useMailmap: configFiles.HasMailmap(),
-		ignoreRevs: ignoreRevs,
-		tally:      f,
-		opts:       opts,
+		revspec:      revspec,
+		pathspecs:    pathspecs,
+		filters:      filters,
+		diffOpts:     diffOpts,
+		useMailmap:   configFiles.HasMailmap(),
+		objectFormat: configFiles.ObjectFormat,
+		ignoreRevs:   ignoreRevs,
+		tally:        f,
+		opts:         opts,
 	}
 
-	buckets, err := tallyFanOutFanIn[tally.TimeSeries](
+	return tallyFanOutFanIn[tally.TimeSeries](
 		ctx,
 		whop,
 		cache,
 		allowProgressBar,
 	)
+}
+
+func TallyCommitsTimeline(
+	ctx context.Context,
+	revspec []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	configFiles config.SupplementalFiles,
+	opts tally.TallyOpts,
+	end time.Time,
+	cache cache.Cache,
+	allowProgressBar bool,
+) ([]tally.TimeBucket, error) {
+	buckets, err := TallyCommitsByDate(
+		ctx,
+		revspec,
+		pathspecs,
+		filters,
+		diffOpts,
+		configFiles,
+		opts,
+		cache,
+		allowProgressBar,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	if end.IsZero() {
-		end = buckets[len(buckets)-1].Time
-	}
-	resolution := tally.CalcResolution(buckets[0].Time, end)
-	rebuckets := tally.Rebucket(buckets, resolution, end)
-	return rebuckets, nil
+	return buckets.Timeline(end), nil
 }
@@ -182,69 +182,97 @@ loop:
 				break loop // We're done, input channel is closed
 			}
 
-			// We pass an empty array of paths here. Even if we are only
-			// tallying commits that affected certain paths, we want to make
-			// sure that the diffs we get include ALL paths touched by each
-			// commit. Otherwise when we cache the commits we would be caching
-			// only a part of the commit
-			nopaths := []string{}
-			subprocess, err := cmd.RunStdinLog(
-				ctx,
-				nopaths,
-				true,
-				whop.useMailmap,
-			)
+			result, err := tallyChunk(ctx, whop, revs, toCache)
 			if err != nil {
 				return err
 			}
 
-			w, stdinCloser := subprocess.StdinWriter()
+			results <- result
+		}
+	}
 
-			// Write revs to git log stdin
-			for _, rev := range revs {
-				fmt.Fprintln(w, rev)
-			}
-			w.Flush()
+	return nil
+}
 
-			err = stdinCloser()
-			if err != nil {
-				return err
-			}
+// Runs git log for a chunk of revisions and tallies the commits.
+//
+// Waits for a free slot first so that tallies running at the same time, e.g.
+// one for each of several repositories, share nCPU git processes between them.
+func tallyChunk[T combinable[T]](
+	ctx context.Context,
+	whop whoperation[T],
+	revs []string,
+	toCache chan<- []git.Commit,
+) (_ T, err error) {
+	var result T
 
-			// Read parsed commits and enqueue for caching
-			result, err := func() (_ T, err error) {
-				var result T
+	select {
+	case <-ctx.Done():
+		return result, errors.New("worker cancelled")
+	case gitSlots <- struct{}{}:
+	}
+	defer func() { <-gitSlots }()
+
+	// We pass an empty array of paths here. Even if we are only
+	// tallying commits that affected certain paths, we want to make
+	// sure that the diffs we get include ALL paths touched by each
+	// commit. Otherwise when we cache the commits we would be caching
+	// only a part of the commit
+	nopaths := []string{}
+	subprocess, err := cmd.RunStdinLog(
+		ctx,
+		nopaths,
+		true,
+		whop.diffOpts,
+		whop.useMailmap,
+	)
+	if err != nil {
+		return result, err
+	}
 
-				lines, finish := subprocess.StdoutNullDelimitedLines()
-				defer func() { err = errors.Join(err, finish()) }()
+	w, stdinCloser := subprocess.StdinWriter()
 
-				commits, finish := git.ParseCommits(lines)
-				defer func() { err = errors.Join(err, finish()) }()
+	// Write revs to git log stdin
+	for _, rev := range revs {
+		fmt.Fprintln(w, rev)
+	}
+	w.Flush()
 
-				commits = cacheTee(commits, toCache)
+	err = stdinCloser()
+	if err != nil {
+		return result, err
+	}
 
-				// Now that we're tallying, we DO care to only look at the file
-				// diffs under the given paths
-				commits, err = git.LimitDiffsByPathspec(commits, whop.pathspecs)
-				if err != nil {
-					return result, err
-				}
+	// Read parsed commits and enqueue for caching
+	result, err = func() (_ T, err error) {
+		var result T
 
-				return whop.tally(commits, whop.opts)
-			}()
+		lines, finish := subprocess.StdoutNullDelimitedLines()
+		defer func() { err = errors.Join(err, finish()) }()
 
-			if err != nil {
-				return err
-			}
+		commits, finish := git.ParseCommits(lines, whop.objectFormat)
+		defer func() { err = errors.Join(err, finish()) }()
 
-			err = subprocess.Wait()
-			if err != nil {
-				return err
-			}
+		commits = cacheTee(commits, toCache)
 
-			results <- result
+		// Now that we're tallying, we DO care to only look at the file
+		// diffs under the given paths
+		commits, err = git.LimitDiffsByPathspec(commits, whop.pathspecs)
+		if err != nil {
+			return result, err
 		}
+
+		return whop.tally(commits, whop.opts)
+	}()
+
+	if err != nil {
+		return result, err
 	}
 
-	return nil
+	err = subprocess.Wait()
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
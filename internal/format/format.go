@@ -5,6 +5,7 @@ package format
 
 import (
 	"fmt"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -84,7 +85,7 @@ func RelativeTime(now time.Time, t time.Time) string {
 // Adds thousands comma and abbreviates numbers > 1m
 func Number(num int) string {
 	if num < 0 {
-		panic("cannot format negative number")
+		return "-" + Number(-num)
 	}
 
 	if num > 100_000_000 {
@@ -104,3 +105,44 @@ func Number(num int) string {
 
 	return fmt.Sprintf("%d", num)
 }
+
+// Formats a percentage with one decimal place, e.g. "42.5%"
+func Percent(p float64) string {
+	return fmt.Sprintf("%.1f%%", p)
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Draws the values as a line of bars scaled to the largest value. Zero values
+// are drawn as spaces.
+func Sparkline(values []int) string {
+	maxValue := 0
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if v <= 0 {
+			b.WriteRune(' ')
+			continue
+		}
+
+		i := (v*len(sparks) - 1) / maxValue
+		b.WriteRune(sparks[i])
+	}
+
+	return b.String()
+}
+
+var shades = []rune("░▒▓█")
+
+// Returns a shading glyph for the value scaled to the largest value. Zero is
+// drawn as a space.
+func Shade(value int, maxValue int) rune {
+	if value <= 0 || maxValue <= 0 {
+		return ' '
+	}
+
+	return shades[(value*len(shades)-1)/maxValue]
+}
@@ -1,6 +1,7 @@
 package format_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +71,21 @@ func TestNumber(t *testing.T) {
 			n:    123_456_789,
 			exp:  ">99m",
 		},
+		{
+			name: "negative_hundreds",
+			n:    -123,
+			exp:  "-123",
+		},
+		{
+			name: "negative_thousands",
+			n:    -957123,
+			exp:  "-957,123",
+		},
+		{
+			name: "negative_millions",
+			n:    -1_234_567,
+			exp:  "-1.2m",
+		},
 	}
 
 	for _, test := range tests {
@@ -82,12 +98,65 @@ func TestNumber(t *testing.T) {
 	}
 }
 
-func TestNumberNegativeError(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Number() did not panic with negative input")
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		p   float64
+		exp string
+	}{
+		{0, "0.0%"},
+		{42.46, "42.5%"},
+		{100, "100.0%"},
+	}
+
+	for _, test := range tests {
+		ans := format.Percent(test.p)
+		if ans != test.exp {
+			t.Errorf("expected %s but got %s", test.exp, ans)
 		}
-	}()
+	}
+}
 
-	format.Number(-1)
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		exp    string
+	}{
+		{
+			name:   "empty",
+			values: []int{},
+			exp:    "",
+		},
+		{
+			name:   "scaled",
+			values: []int{1, 4, 0, 8, 2},
+			exp:    "▁▄ █▂",
+		},
+		{
+			name:   "all_same",
+			values: []int{3, 3},
+			exp:    "██",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := format.Sparkline(test.values)
+			if s != test.exp {
+				t.Errorf("expected %q but got %q", test.exp, s)
+			}
+		})
+	}
+}
+
+func TestShade(t *testing.T) {
+	var b strings.Builder
+	for _, v := range []int{0, 1, 2, 4, 5, 7, 8} {
+		b.WriteRune(format.Shade(v, 8))
+	}
+
+	exp := " ░░▒▓██"
+	if b.String() != exp {
+		t.Errorf("expected %q but got %q", exp, b.String())
+	}
 }
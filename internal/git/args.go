@@ -11,12 +11,26 @@ import (
 
 // Handles splitting the Git revisions from the pathspecs given a list of args.
 //
-// We call git rev-parse to disambiguate.
+// We call git rev-parse to disambiguate. Git prints the revisions as full
+// hashes, so we also have it print the repository's object format to know how
+// long those are. Otherwise a pathspec that happens to look like a hash of the
+// other object format could be taken for a revision.
 func ParseArgs(args []string) (revs []string, pathspecs []string, err error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	return ParseArgsContext(context.Background(), args)
+}
+
+// Like ParseArgs(), but runs Git with the given context.
+func ParseArgsContext(
+	ctx context.Context,
+	args []string,
+) (revs []string, pathspecs []string, err error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	subprocess, err := cmd.RunRevParse(ctx, args)
+	subprocess, err := cmd.RunRevParse(
+		ctx,
+		append([]string{"--show-object-format"}, args...),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not parse args: %w", err)
 	}
@@ -26,9 +40,16 @@ func ParseArgs(args []string) (revs []string, pathspecs []string, err error) {
 	revs = []string{}
 	pathspecs = []string{}
 
+	var format rev.ObjectFormat
 	pastRevs := false
 	for line := range lines {
-		if !pastRevs && rev.IsFullHash(line) {
+		if len(format) == 0 {
+			// Object format comes first
+			format = rev.ObjectFormat(line)
+			continue
+		}
+
+		if !pastRevs && rev.IsFullHash(line, format) {
 			revs = append(revs, line)
 		} else {
 			pastRevs = true
@@ -0,0 +1,168 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+)
+
+// Someone credited with a commit in place of its author.
+type Author struct {
+	Name  string
+	Email string
+}
+
+// A commit in the commit graph, without any of its diffs.
+type GraphCommit struct {
+	Hash    string
+	Parents []string
+	Author  Author
+}
+
+// Returns the author of the branch merged by each merge commit in the
+// first-parent history of the given revisions, keyed by the full hash of the
+// merge commit.
+//
+// See BranchAuthors().
+func MergedBranchAuthors(
+	ctx context.Context,
+	revs []string,
+	configFiles config.SupplementalFiles,
+) (_ map[string]Author, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error finding authors of merged branches: %w", err)
+		}
+	}()
+
+	subprocess, err := cmd.RunLogGraph(ctx, revs, configFiles.HasMailmap())
+	if err != nil {
+		return nil, err
+	}
+
+	graph := []GraphCommit{}
+
+	lines, finish := subprocess.StdoutLines()
+	for line := range lines {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected line in commit graph: %q", line)
+		}
+
+		graph = append(graph, GraphCommit{
+			Hash:    fields[0],
+			Parents: strings.Fields(fields[1]),
+			Author:  Author{Name: fields[2], Email: fields[3]},
+		})
+	}
+
+	err = finish()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return BranchAuthors(graph), nil
+}
+
+// Returns the author of the branch merged by each merge commit in the
+// first-parent history of the graph, keyed by the hash of the merge commit.
+//
+// The graph must list parents before their children. The first-parent history
+// starts at each commit that is not the parent of another commit in the graph.
+//
+// A merge commit's branch is made up of the commits it brought into the
+// first-parent history, i.e. those reachable from the merge commit but not
+// from its first parent. The branch's author is whoever authored the most of
+// those commits, or if there is a tie, whoever authored the earliest of them.
+// Merges that brought in no new commits are left out.
+func BranchAuthors(graph []GraphCommit) map[string]Author {
+	byHash := map[string]GraphCommit{}
+	order := map[string]int{}
+	isParent := map[string]bool{}
+	for i, c := range graph {
+		byHash[c.Hash] = c
+		order[c.Hash] = i
+		for _, p := range c.Parents {
+			isParent[p] = true
+		}
+	}
+
+	// Walk first parents back from each tip to find the first-parent history
+	mainline := map[string]bool{}
+	for _, c := range graph {
+		if isParent[c.Hash] {
+			continue
+		}
+
+		for {
+			mainline[c.Hash] = true
+			if len(c.Parents) == 0 {
+				break
+			}
+
+			parent, ok := byHash[c.Parents[0]]
+			if !ok || mainline[parent.Hash] {
+				break
+			}
+			c = parent
+		}
+	}
+
+	// Since parents come first, each merge claims the commits it brought in
+	// before any later merge can. Any commit reachable from a merge's first
+	// parent has then already been claimed or is in the first-parent history.
+	claimed := map[string]bool{}
+	authors := map[string]Author{}
+	for _, merge := range graph {
+		if !mainline[merge.Hash] || len(merge.Parents) < 2 {
+			continue
+		}
+
+		counts := map[Author]int{}
+		earliest := map[Author]int{}
+
+		stack := append([]string{}, merge.Parents[1:]...)
+		for len(stack) > 0 {
+			hash := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			c, ok := byHash[hash]
+			if !ok || mainline[hash] || claimed[hash] {
+				continue
+			}
+			claimed[hash] = true
+
+			counts[c.Author] += 1
+			if i, ok := earliest[c.Author]; !ok || order[hash] < i {
+				earliest[c.Author] = order[hash]
+			}
+
+			stack = append(stack, c.Parents...)
+		}
+
+		var best Author
+		found := false
+		for author, n := range counts {
+			if !found ||
+				n > counts[best] ||
+				n == counts[best] && earliest[author] < earliest[best] {
+				best = author
+				found = true
+			}
+		}
+
+		if found {
+			authors[merge.Hash] = best
+		}
+	}
+
+	return authors
+}
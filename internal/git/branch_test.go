@@ -0,0 +1,65 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+func TestBranchAuthors(t *testing.T) {
+	ann := git.Author{Name: "Ann", Email: "ann@mail.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@mail.com"}
+	cat := git.Author{Name: "Cat", Email: "cat@mail.com"}
+
+	// a - b ------- m1 ----------- m2 ---- m3
+	//      \       /              /       /
+	//       c1 - c2 - d1 ------- d2      |
+	//        \                           |
+	//         e1 ----------------------- e2
+	graph := []git.GraphCommit{
+		{Hash: "a", Author: ann},
+		{Hash: "b", Parents: []string{"a"}, Author: ann},
+		{Hash: "c1", Parents: []string{"b"}, Author: bob},
+		{Hash: "c2", Parents: []string{"c1"}, Author: cat},
+		{Hash: "m1", Parents: []string{"b", "c2"}, Author: ann},
+		{Hash: "d1", Parents: []string{"c2"}, Author: cat},
+		{Hash: "d2", Parents: []string{"d1"}, Author: cat},
+		{Hash: "m2", Parents: []string{"m1", "d2"}, Author: ann},
+		{Hash: "e1", Parents: []string{"c1"}, Author: bob},
+		{Hash: "e2", Parents: []string{"e1"}, Author: cat},
+		{Hash: "m3", Parents: []string{"m2", "e2"}, Author: ann},
+	}
+
+	authors := git.BranchAuthors(graph)
+
+	expected := map[string]git.Author{
+		"m1": bob, // Tie between Bob and Cat goes to earliest commit
+		"m2": cat, // c2 was already merged by m1
+		"m3": bob, // Tie again, since c1 was already merged by m1
+	}
+	if diff := cmp.Diff(expected, authors); diff != "" {
+		t.Errorf("branch authors are wrong:\n%s", diff)
+	}
+}
+
+func TestBranchAuthorsNothingMerged(t *testing.T) {
+	ann := git.Author{Name: "Ann", Email: "ann@mail.com"}
+	bob := git.Author{Name: "Bob", Email: "bob@mail.com"}
+
+	// Second merge of the same branch brings in nothing new
+	graph := []git.GraphCommit{
+		{Hash: "a", Author: ann},
+		{Hash: "b", Parents: []string{"a"}, Author: bob},
+		{Hash: "m1", Parents: []string{"a", "b"}, Author: ann},
+		{Hash: "m2", Parents: []string{"m1", "b"}, Author: ann},
+	}
+
+	authors := git.BranchAuthors(graph)
+
+	expected := map[string]git.Author{"m1": bob}
+	if diff := cmp.Diff(expected, authors); diff != "" {
+		t.Errorf("branch authors are wrong:\n%s", diff)
+	}
+}
@@ -10,18 +10,31 @@ import (
 	"slices"
 )
 
+// Trailers are unfolded and separated by the ASCII unit separator so that all
+// of a commit's trailers come out on a single line.
+const trailersFormat = "%(trailers:" +
+	"key=Reviewed-by,key=Acked-by,key=Tested-by,key=Signed-off-by," +
+	"unfold,separator=%x1f)%x00"
+
 const (
-	logFormat        = "--pretty=format:%H%x00%h%x00%p%x00%an%x00%ae%x00%ad%x00"
-	mailmapLogFormat = "--pretty=format:%H%x00%h%x00%p%x00%aN%x00%aE%x00%ad%x00"
+	logFormat = "--pretty=format:%H%x00%h%x00%p%x00" +
+		"%an%x00%ae%x00%ad%x00" +
+		"%cn%x00%ce%x00%cd%x00" +
+		trailersFormat
+	mailmapLogFormat = "--pretty=format:%H%x00%h%x00%p%x00" +
+		"%aN%x00%aE%x00%ad%x00" +
+		"%cN%x00%cE%x00%cd%x00" +
+		trailersFormat
 )
 
-// Runs git log
+// Runs git log. Commits are printed in topological order, parents first.
 func RunLog(
 	ctx context.Context,
 	revs []string,
 	pathspecs []string,
 	filters LogFilters,
 	needDiffs bool,
+	diffOpts DiffOpts,
 	useMailmap bool,
 ) (*Subprocess, error) {
 	var baseArgs []string
@@ -31,8 +44,9 @@ func RunLog(
 			"log",
 			mailmapLogFormat,
 			"-z",
-			"--date=unix",
+			"--date=raw",
 			"--reverse",
+			"--topo-order",
 			"--no-show-signature",
 		}
 	} else {
@@ -40,15 +54,17 @@ func RunLog(
 			"log",
 			logFormat,
 			"-z",
-			"--date=unix",
+			"--date=raw",
 			"--reverse",
+			"--topo-order",
 			"--no-show-signature",
 			"--no-mailmap",
 		}
 	}
 
 	if needDiffs {
-		baseArgs = append(baseArgs, "--numstat")
+		baseArgs = append(baseArgs, "--numstat", "--find-renames")
+		baseArgs = append(baseArgs, diffOpts.ToArgs()...)
 	}
 
 	filterArgs := filters.ToArgs()
@@ -80,6 +96,7 @@ func RunStdinLog(
 	ctx context.Context,
 	pathspecs []string, // Doesn't limit commits, but limits diffs!
 	needDiffs bool,
+	diffOpts DiffOpts,
 	useMailmap bool,
 ) (*Subprocess, error) {
 	var baseArgs []string
@@ -89,7 +106,7 @@ func RunStdinLog(
 			"log",
 			mailmapLogFormat,
 			"-z",
-			"--date=unix",
+			"--date=raw",
 			"--reverse",
 			"--no-show-signature",
 			"--stdin",
@@ -100,7 +117,7 @@ func RunStdinLog(
 			"log",
 			logFormat,
 			"-z",
-			"--date=unix",
+			"--date=raw",
 			"--reverse",
 			"--no-show-signature",
 			"--stdin",
@@ -110,7 +127,8 @@ func RunStdinLog(
 	}
 
 	if needDiffs {
-		baseArgs = append(baseArgs, "--numstat")
+		baseArgs = append(baseArgs, "--numstat", "--find-renames")
+		baseArgs = append(baseArgs, diffOpts.ToArgs()...)
 	}
 
 	var args []string
@@ -157,6 +175,31 @@ func RunRevParseTopLevel(ctx context.Context) (*Subprocess, error) {
 	return subprocess, nil
 }
 
+// Runs git ls-tree, listing every entry in the tree recursively.
+func RunLsTree(ctx context.Context, rev string) (*Subprocess, error) {
+	args := []string{"ls-tree", "-r", "-z", rev}
+
+	needStdin := false
+	subprocess, err := run(ctx, args, needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git ls-tree: %w", err)
+	}
+
+	return subprocess, nil
+}
+
+func RunRevParseObjectFormat(ctx context.Context) (*Subprocess, error) {
+	var args = []string{"rev-parse", "--show-object-format"}
+
+	needStdin := false
+	subprocess, err := run(ctx, args, needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git rev-parse: %w", err)
+	}
+
+	return subprocess, nil
+}
+
 // Runs git rev-list. When countOnly is true, passes --count, which is much
 // faster than printing then getting all the revisions when all you need is the
 // count.
@@ -173,6 +216,7 @@ func RunRevList(
 	baseArgs := []string{
 		"rev-list",
 		"--reverse",
+		"--topo-order",
 	}
 
 	filterArgs := filters.ToArgs()
@@ -199,13 +243,99 @@ func RunRevList(
 	return subprocess, nil
 }
 
-func RunLsFiles(ctx context.Context, pathspecs []string) (*Subprocess, error) {
+// Runs git log to print the commit graph, one commit per line with its full
+// hash, the hashes of its parents, and its author name and email, each
+// separated by a null byte. Parents come before their children.
+func RunLogGraph(
+	ctx context.Context,
+	revs []string,
+	useMailmap bool,
+) (*Subprocess, error) {
+	var baseArgs []string
+
+	if useMailmap {
+		baseArgs = []string{
+			"log",
+			"--pretty=format:%H%x00%P%x00%aN%x00%aE",
+			"--topo-order",
+			"--reverse",
+			"--no-show-signature",
+		}
+	} else {
+		baseArgs = []string{
+			"log",
+			"--pretty=format:%H%x00%P%x00%an%x00%ae",
+			"--topo-order",
+			"--reverse",
+			"--no-show-signature",
+			"--no-mailmap",
+		}
+	}
+
+	args := slices.Concat(baseArgs, revs, []string{"--"})
+
+	needStdin := false
+	subprocess, err := run(ctx, args, needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	return subprocess, nil
+}
+
+// Runs git log to print the full hash and raw message of every commit with a
+// Co-authored-by line anywhere in its message, each followed by a null byte.
+//
+// Only commits touching the given paths and passing the given filters are
+// listed, like the commits we tally.
+func RunLogCoAuthored(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters LogFilters,
+) (*Subprocess, error) {
+	baseArgs := []string{
+		"log",
+		"--pretty=format:%H%x00%B",
+		"-z",
+		"--regexp-ignore-case",
+		"--extended-regexp",
+		"--grep=^[[:space:]]*co-authored-by:",
+		"--no-show-signature",
+	}
+
+	args := slices.Concat(
+		baseArgs,
+		filters.ToArgs(),
+		revs,
+		[]string{"--"},
+		pathspecs,
+	)
+
+	needStdin := false
+	subprocess, err := run(ctx, args, needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	return subprocess, nil
+}
+
+func RunLsFiles(
+	ctx context.Context,
+	pathspecs []string,
+	recurseSubmodules bool,
+) (*Subprocess, error) {
 	baseArgs := []string{
 		"ls-files",
 		"--exclude-standard",
 		"-z",
 	}
 
+	if recurseSubmodules {
+		baseArgs = append(baseArgs, "--recurse-submodules")
+	}
+
 	var args []string
 	if len(pathspecs) > 0 {
 		args = slices.Concat(baseArgs, pathspecs)
@@ -233,3 +363,33 @@ func RunConfigGet(ctx context.Context, args []string) (*Subprocess, error) {
 
 	return subprocess, nil
 }
+
+// Runs git config --get-all, which prints every value of a multi-valued option
+func RunConfigGetAll(ctx context.Context, args []string) (*Subprocess, error) {
+	baseArgs := []string{"config", "--get-all"}
+
+	needStdin := false
+	subprocess, err := run(ctx, slices.Concat(baseArgs, args), needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git config --get-all: %w", err)
+	}
+
+	return subprocess, nil
+}
+
+// Runs git cat-file --batch-check, printing only the full object name for each
+// object named on stdin.
+//
+// Objects that cannot be found (or whose names are ambiguous) are printed as
+// the name given followed by "missing" or "ambiguous".
+func RunCatFileBatchCheck(ctx context.Context) (*Subprocess, error) {
+	args := []string{"cat-file", "--batch-check=%(objectname)"}
+
+	needStdin := true
+	subprocess, err := run(ctx, args, needStdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git cat-file: %w", err)
+	}
+
+	return subprocess, nil
+}
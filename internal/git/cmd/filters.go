@@ -10,6 +10,12 @@ type LogFilters struct {
 	Until    string
 	Authors  []string
 	Nauthors []string
+	// Given a symmetric difference A...B, keep only the commits on the B side
+	// whose changes are not also on the A side, as judged by patch ID
+	OmitCherryPicks bool
+	// Follow only the first parent of merge commits, so that each merge stands
+	// in for the branch it merged
+	FirstParent bool
 }
 
 // Turn into CLI args we can pass to `git log`
@@ -44,5 +50,41 @@ func (f LogFilters) ToArgs() []string {
 		args = append(args, "--author", regex)
 	}
 
+	if f.OmitCherryPicks {
+		args = append(args, "--cherry-pick", "--right-only")
+	}
+
+	if f.FirstParent {
+		args = append(args, "--first-parent")
+	}
+
+	return args
+}
+
+// Options that change how git log computes the diff for each commit.
+type DiffOpts struct {
+	IgnoreSpace      bool
+	IgnoreBlankLines bool
+	// Diff merge commits against their first parent. Without this, merge
+	// commits have no diff
+	FirstParentMerges bool
+}
+
+// Turn into CLI args we can pass to `git log`
+func (o DiffOpts) ToArgs() []string {
+	args := []string{}
+
+	if o.IgnoreSpace {
+		args = append(args, "--ignore-all-space")
+	}
+
+	if o.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+
+	if o.FirstParentMerges {
+		args = append(args, "--diff-merges=first-parent")
+	}
+
 	return args
 }
@@ -0,0 +1,55 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+)
+
+func TestDiffOptsToArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     cmd.DiffOpts
+		expected []string
+	}{
+		{
+			name:     "none",
+			opts:     cmd.DiffOpts{},
+			expected: []string{},
+		},
+		{
+			name:     "ignore_space",
+			opts:     cmd.DiffOpts{IgnoreSpace: true},
+			expected: []string{"--ignore-all-space"},
+		},
+		{
+			name:     "ignore_blank_lines",
+			opts:     cmd.DiffOpts{IgnoreBlankLines: true},
+			expected: []string{"--ignore-blank-lines"},
+		},
+		{
+			name: "all",
+			opts: cmd.DiffOpts{
+				IgnoreSpace:       true,
+				IgnoreBlankLines:  true,
+				FirstParentMerges: true,
+			},
+			expected: []string{
+				"--ignore-all-space",
+				"--ignore-blank-lines",
+				"--diff-merges=first-parent",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := test.opts.ToArgs()
+			if diff := cmp.Diff(test.expected, args); diff != "" {
+				t.Errorf("args are wrong:\n%s", diff)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"iter"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
@@ -46,6 +47,43 @@ func (s Subprocess) StdinWriter() (_ *bufio.Writer, closer func() error) {
 	}
 }
 
+// Writes the given lines to stdin from another goroutine, closing stdin once
+// all the lines have been written.
+//
+// Use this instead of StdinWriter() when the subprocess prints output as it
+// reads its input. Writing everything before reading any output can otherwise
+// deadlock once the stdout pipe fills up.
+//
+// The returned function waits for the writing to finish.
+func (s Subprocess) WriteStdinLines(lines []string) (wait func() error) {
+	done := make(chan error, 1)
+
+	go func() {
+		w, closer := s.StdinWriter()
+		for _, line := range lines {
+			_, err := fmt.Fprintln(w, line)
+			if err != nil {
+				closer()
+				done <- fmt.Errorf("error writing to stdin: %w", err)
+				return
+			}
+		}
+
+		err := w.Flush()
+		if err != nil {
+			closer()
+			done <- fmt.Errorf("error writing to stdin: %w", err)
+			return
+		}
+
+		done <- closer()
+	}()
+
+	return func() error {
+		return <-done
+	}
+}
+
 func (s Subprocess) StdoutText() (string, error) {
 	b, err := io.ReadAll(s.stdout)
 	if err != nil {
@@ -159,12 +197,39 @@ func (s Subprocess) Wait() error {
 	return nil
 }
 
+type dirKey struct{}
+
+// Returns a context that runs any Git subprocesses started with it in the
+// given directory rather than the current working directory.
+//
+// This lets us run Git in a submodule.
+func WithDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, dirKey{}, dir)
+}
+
+type configKey struct{}
+
+// Returns a context that sets the given configuration variable for any Git
+// subprocesses started with it, as if by "git -c key=value".
+func WithConfig(ctx context.Context, key string, value string) context.Context {
+	config, _ := ctx.Value(configKey{}).([]string)
+	config = slices.Concat(config, []string{"-c", key + "=" + value})
+	return context.WithValue(ctx, configKey{}, config)
+}
+
 func run(
 	ctx context.Context,
 	args []string,
 	needStdin bool,
 ) (*Subprocess, error) {
+	if config, ok := ctx.Value(configKey{}).([]string); ok {
+		args = slices.Concat(config, args)
+	}
+
 	cmd := exec.CommandContext(ctx, "git", args...)
+	if dir, ok := ctx.Value(dirKey{}).(string); ok {
+		cmd.Dir = dir
+	}
 	logger().Debug("running subprocess", "cmd", cmd)
 
 	stdout, err := cmd.StdoutPipe()
@@ -9,8 +9,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	rev "github.com/sinclairtarget/git-who/internal/git/revision"
 )
 
 func repoMailmapPath(gitRootPath string) string {
@@ -19,8 +22,8 @@ func repoMailmapPath(gitRootPath string) string {
 }
 
 // Looks up a file pointed to by the mailmap.file setting in the git config.
-func globalMailmapPath() (string, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+func ConfiguredMailmapPath(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	subprocess, err := cmd.RunConfigGet(
@@ -55,18 +58,177 @@ func globalMailmapPath() (string, error) {
 	return p, nil
 }
 
-// NOTE: We do NOT respect the blame.ignoreRevsFile option in the git config
-// here, we just assume the conventional path for this file in the repo.
-//
-// The option can be specified multiple times which makes it a tad complicated.
-func ignoreRevsPath(gitRootPath string) string {
+// Looks up the score expression for a named profile set with the
+// who.score.<name> setting in the git config. Returns an empty string if the
+// profile is not configured.
+func ScoreProfile(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subprocess, err := cmd.RunConfigGet(
+		ctx,
+		[]string{"who.score." + name},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	expr, err := subprocess.StdoutText()
+	if err != nil {
+		return "", err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		var subprocessErr *cmd.SubprocessErr
+		if errors.As(err, &subprocessErr) {
+			logger().Debug(
+				"failed to get score profile from config or value not present",
+				"name",
+				name,
+				"exitcode",
+				subprocessErr.ExitCode,
+			)
+			expr = ""
+		} else {
+			logger().Debug("got unknown error")
+			return "", err
+		}
+	}
+
+	return expr, nil
+}
+
+// The conventional location of the ignore revs file in the repo.
+func defaultIgnoreRevsPath(gitRootPath string) string {
 	path := filepath.Join(gitRootPath, ".git-blame-ignore-revs")
 	return path
 }
 
-// Checks to see whether the files exist on disk or not
+// Looks up all the files pointed to by the blame.ignoreRevsFile setting in the
+// git config.
+//
+// The option can be specified multiple times. Like git blame, we treat an
+// empty value as resetting the list of files specified so far. Relative paths
+// are relative to the root of the repository.
+func configIgnoreRevsPaths(
+	ctx context.Context,
+	gitRootPath string,
+) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subprocess, err := cmd.RunConfigGetAll(
+		ctx,
+		[]string{"--type=path", "blame.ignoreRevsFile"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+
+	lines, finish := subprocess.StdoutLines()
+	for line := range lines {
+		p := strings.TrimSpace(line)
+		if len(p) == 0 {
+			paths = []string{}
+			continue
+		}
+
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(gitRootPath, p)
+		}
+
+		paths = append(paths, p)
+	}
+
+	err = finish()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		var subprocessErr *cmd.SubprocessErr
+		if errors.As(err, &subprocessErr) {
+			logger().Debug(
+				"failed to get ignore revs files from config or value not present",
+				"exitcode",
+				subprocessErr.ExitCode,
+			)
+			paths = []string{}
+		} else {
+			logger().Debug("got unknown error")
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// Looks up the hash algorithm used by the repository.
+//
+// Versions of Git older than 2.29 don't know about SHA-256 and echo back the
+// --show-object-format flag, so we assume SHA-1 for any output we don't
+// recognize.
+func objectFormat(ctx context.Context) (rev.ObjectFormat, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subprocess, err := cmd.RunRevParseObjectFormat(ctx)
+	if err != nil {
+		return rev.SHA1, err
+	}
+
+	text, err := subprocess.StdoutText()
+	if err != nil {
+		return rev.SHA1, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return rev.SHA1, err
+	}
+
+	format := rev.ObjectFormat(strings.TrimSpace(text))
+	if format != rev.SHA1 && format != rev.SHA256 {
+		logger().Debug("unrecognized object format", "output", text)
+		return rev.SHA1, nil
+	}
+
+	return format, nil
+}
+
+// Checks to see whether the files exist on disk or not.
+//
+// ignoreRevsFiles are additional ignore revs files given explicitly by the
+// user. Unlike the files we discover on our own, it is an error if any of
+// these do not exist.
 func DetectSupplementalFiles(
 	gitRootPath string,
+	ignoreRevsFiles []string,
+) (SupplementalFiles, error) {
+	return detectFiles(context.Background(), gitRootPath, ignoreRevsFiles)
+}
+
+// Like DetectSupplementalFiles(), but runs Git with the given context.
+//
+// When checking a repository other than the one in the working directory, the
+// context should run Git in that repository (see cmd.WithDir()) so that we pick
+// up its configuration.
+func DetectSupplementalFilesContext(
+	ctx context.Context,
+	gitRootPath string,
+	ignoreRevsFiles []string,
+) (SupplementalFiles, error) {
+	return detectFiles(ctx, gitRootPath, ignoreRevsFiles)
+}
+
+func detectFiles(
+	ctx context.Context,
+	gitRootPath string,
+	ignoreRevsFiles []string,
 ) (_ SupplementalFiles, err error) {
 	defer func() {
 		if err != nil {
@@ -79,6 +241,11 @@ func DetectSupplementalFiles(
 
 	var files SupplementalFiles
 
+	files.ObjectFormat, err = objectFormat(ctx)
+	if err != nil {
+		return files, err
+	}
+
 	// Repo-local mailmap
 	mailmapPath := repoMailmapPath(gitRootPath)
 	_, err = os.Stat(mailmapPath)
@@ -89,7 +256,7 @@ func DetectSupplementalFiles(
 	}
 
 	// Git config mailmap
-	mailmapPath, err = globalMailmapPath()
+	mailmapPath, err = ConfiguredMailmapPath(ctx)
 	if err != nil {
 		return files, err
 	}
@@ -103,14 +270,42 @@ func DetectSupplementalFiles(
 		}
 	}
 
-	// Repo-local git blame ignore revs file
-	ignoreRevsPath := ignoreRevsPath(gitRootPath)
-	_, err = os.Stat(ignoreRevsPath)
-	if err == nil {
-		files.IgnoreRevsPath = ignoreRevsPath
-	} else if !errors.Is(err, os.ErrNotExist) {
+	// Git blame ignore revs files, both the repo-local file at the
+	// conventional path and any configured with blame.ignoreRevsFile
+	configPaths, err := configIgnoreRevsPaths(ctx, gitRootPath)
+	if err != nil {
 		return files, err
 	}
 
+	candidates := slices.Concat(
+		[]string{defaultIgnoreRevsPath(gitRootPath)},
+		configPaths,
+	)
+	for _, p := range candidates {
+		_, err = os.Stat(p)
+		if err == nil {
+			files.addIgnoreRevsPath(p)
+		} else if errors.Is(err, os.ErrNotExist) {
+			logger().Debug("ignore revs file does not exist", "path", p)
+		} else {
+			return files, err
+		}
+	}
+
+	// Ignore revs files given explicitly
+	for _, p := range ignoreRevsFiles {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return files, err
+		}
+
+		_, err = os.Stat(absPath)
+		if err != nil {
+			return files, fmt.Errorf("could not read ignore revs file: %w", err)
+		}
+
+		files.addIgnoreRevsPath(absPath)
+	}
+
 	return files, nil
 }
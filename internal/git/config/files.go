@@ -2,14 +2,17 @@ package config
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
 	"io/fs"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
 	rev "github.com/sinclairtarget/git-who/internal/git/revision"
 )
 
@@ -17,7 +20,8 @@ import (
 type SupplementalFiles struct {
 	RepoMailmapPath   string
 	GlobalMailmapPath string
-	IgnoreRevsPath    string
+	IgnoreRevsPaths   []string
+	ObjectFormat      rev.ObjectFormat // Needed to read the ignore revs files
 }
 
 func (sf SupplementalFiles) HasMailmap() bool {
@@ -25,7 +29,13 @@ func (sf SupplementalFiles) HasMailmap() bool {
 }
 
 func (sf SupplementalFiles) HasIgnoreRevs() bool {
-	return len(sf.IgnoreRevsPath) > 0
+	return len(sf.IgnoreRevsPaths) > 0
+}
+
+func (sf *SupplementalFiles) addIgnoreRevsPath(p string) {
+	if !slices.Contains(sf.IgnoreRevsPaths, p) {
+		sf.IgnoreRevsPaths = append(sf.IgnoreRevsPaths, p)
+	}
 }
 
 func (sf SupplementalFiles) MailmapHash(h hash.Hash32) error {
@@ -62,39 +72,166 @@ func (sf SupplementalFiles) MailmapHash(h hash.Hash32) error {
 	return nil
 }
 
-// Get git blame ignored revisions
-func (sf SupplementalFiles) IgnoreRevs() (_ []string, err error) {
+func (sf SupplementalFiles) IgnoreRevsHash(h hash.Hash32) error {
+	for _, p := range sf.IgnoreRevsPaths {
+		f, err := os.Open(p)
+		if !errors.Is(err, fs.ErrNotExist) {
+			if err != nil {
+				return fmt.Errorf("could not read ignore revs file: %v", err)
+			}
+			defer f.Close()
+
+			_, err = io.Copy(h, f)
+			if err != nil {
+				return fmt.Errorf("error hashing ignore revs file: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get git blame ignored revisions from all the ignore revs files.
+//
+// Abbreviated hashes are resolved to full hashes. Any that cannot be resolved
+// are skipped.
+func (sf SupplementalFiles) IgnoreRevs(
+	ctx context.Context,
+) (_ []string, err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("error reading git blame ignore revs: %w", err)
 		}
 	}()
 
-	var revs []string
+	revs := []string{}
+	abbrevs := []string{}
+
+	for _, p := range sf.IgnoreRevsPaths {
+		fileRevs, fileAbbrevs, err := readIgnoreRevsFile(p, sf.ObjectFormat)
+		if err != nil {
+			return revs, err
+		}
+
+		revs = append(revs, fileRevs...)
+		abbrevs = append(abbrevs, fileAbbrevs...)
+	}
+
+	if len(abbrevs) > 0 {
+		resolved, err := resolveAbbrevs(ctx, abbrevs, sf.ObjectFormat)
+		if err != nil {
+			return revs, err
+		}
 
-	if !sf.HasIgnoreRevs() {
-		return revs, nil
+		revs = append(revs, resolved...)
 	}
 
-	f, err := os.Open(sf.IgnoreRevsPath)
+	return revs, nil
+}
+
+// Returns the full hashes and the abbreviated hashes listed in the file.
+func readIgnoreRevsFile(
+	p string,
+	format rev.ObjectFormat,
+) (revs []string, abbrevs []string, err error) {
+	f, err := os.Open(p)
 	if err != nil {
-		return revs, err
+		return nil, nil, err
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
 		// Comments starting with "#" are allowed in the ignore revs file
-		if rev.IsFullHash(line) {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+
+		if rev.IsFullHash(line, format) {
 			revs = append(revs, line)
+		} else if rev.IsAbbrevHash(line, format) {
+			abbrevs = append(abbrevs, line)
+		} else if len(line) > 0 {
+			logger().Debug(
+				"skipping unrecognized line in ignore revs file",
+				"path",
+				p,
+				"line",
+				line,
+			)
 		}
 	}
 
 	err = scanner.Err()
 	if err != nil {
-		return revs, err
+		return nil, nil, err
+	}
+
+	return revs, abbrevs, nil
+}
+
+// Turns abbreviated hashes into full hashes using git cat-file.
+//
+// We use cat-file rather than rev-parse because rev-parse gives up at the
+// first name it cannot resolve, whereas we only want to skip that one.
+func resolveAbbrevs(
+	ctx context.Context,
+	abbrevs []string,
+	format rev.ObjectFormat,
+) (_ []string, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("failed to resolve abbreviated hashes: %w", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subprocess, err := cmd.RunCatFileBatchCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(abbrevs))
+	for i, abbrev := range abbrevs {
+		names[i] = abbrev + "^{commit}"
+	}
+	waitStdin := subprocess.WriteStdinLines(names)
+
+	revs := []string{}
+
+	// We get one line of output for each line of input
+	i := 0
+	lines, finish := subprocess.StdoutLines()
+	for line := range lines {
+		if rev.IsFullHash(line, format) {
+			revs = append(revs, line)
+		} else if i < len(abbrevs) {
+			logger().Debug(
+				"could not resolve ignore rev",
+				"abbrev",
+				abbrevs[i],
+				"output",
+				line,
+			)
+		}
+
+		i += 1
+	}
+
+	err = finish()
+	if err != nil {
+		return nil, err
+	}
+
+	err = waitStdin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return nil, err
 	}
 
 	return revs, nil
@@ -19,13 +19,18 @@ import (
 )
 
 type Commit struct {
-	Hash        string
-	ShortHash   string
-	IsMerge     bool
-	AuthorName  string
-	AuthorEmail string
-	Date        time.Time
-	FileDiffs   []FileDiff
+	Hash           string
+	ShortHash      string
+	IsMerge        bool
+	AuthorName     string
+	AuthorEmail    string
+	Date           time.Time // Author date
+	AuthorOffset   int       // Author's UTC offset in seconds east
+	CommitterName  string
+	CommitterEmail string
+	CommitterDate  time.Time
+	Trailers       []Trailer
+	FileDiffs      []FileDiff
 }
 
 func (c Commit) Name() string {
@@ -38,25 +43,67 @@ func (c Commit) Name() string {
 	}
 }
 
+// The author date in the author's own time zone.
+func (c Commit) AuthorLocalDate() time.Time {
+	return c.Date.In(time.FixedZone("", c.AuthorOffset))
+}
+
 func (c Commit) String() string {
 	return fmt.Sprintf(
-		"{ hash:%s author:%s <%s> date:%s merge:%v }",
+		"{ hash:%s author:%s <%s> date:%s committer:%s <%s> merge:%v }",
 		c.Name(),
 		c.AuthorName,
 		c.AuthorEmail,
 		c.Date.Format("Jan 2, 2006"),
+		c.CommitterName,
+		c.CommitterEmail,
 		c.IsMerge,
 	)
 }
 
+// Trailer keys we parse from commit messages.
+const (
+	ReviewedByTrailer  = "Reviewed-by"
+	AckedByTrailer     = "Acked-by"
+	TestedByTrailer    = "Tested-by"
+	SignedOffByTrailer = "Signed-off-by"
+)
+
+// A trailer at the end of a commit message crediting someone for their part in
+// the commit, e.g. "Reviewed-by: Jane Doe <jane@example.com>".
+type Trailer struct {
+	Key   string
+	Name  string
+	Email string
+}
+
+func (t Trailer) String() string {
+	return fmt.Sprintf("{ %s:%s <%s> }", t.Key, t.Name, t.Email)
+}
+
 // A file that was changed in a Commit.
 type FileDiff struct {
 	Path         string
+	OldPath      string // Path before the commit, if the file was renamed
 	LinesAdded   int
 	LinesRemoved int
 }
 
+func (d FileDiff) IsRename() bool {
+	return len(d.OldPath) > 0 && d.OldPath != d.Path
+}
+
 func (d FileDiff) String() string {
+	if d.IsRename() {
+		return fmt.Sprintf(
+			"{ path:\"%s\" from:\"%s\" added:%d removed:%d }",
+			d.Path,
+			d.OldPath,
+			d.LinesAdded,
+			d.LinesRemoved,
+		)
+	}
+
 	return fmt.Sprintf(
 		"{ path:\"%s\" added:%d removed:%d }",
 		d.Path,
@@ -73,6 +120,7 @@ func CommitsWithOpts(
 	pathspecs []string,
 	filters cmd.LogFilters,
 	populateDiffs bool,
+	diffOpts cmd.DiffOpts,
 	configFiles config.SupplementalFiles,
 ) (
 	iter.Seq[Commit],
@@ -80,7 +128,7 @@ func CommitsWithOpts(
 ) {
 	empty := slices.Values([]Commit{})
 
-	ignoreRevs, err := configFiles.IgnoreRevs()
+	ignoreRevs, err := configFiles.IgnoreRevs(ctx)
 	if err != nil {
 		return empty, func() error { return err }
 	}
@@ -91,6 +139,7 @@ func CommitsWithOpts(
 		pathspecs,
 		filters,
 		populateDiffs,
+		diffOpts,
 		configFiles.HasMailmap(),
 	)
 	if err != nil {
@@ -98,7 +147,7 @@ func CommitsWithOpts(
 	}
 
 	lines, finishLines := subprocess.StdoutNullDelimitedLines()
-	commits, finishCommits := ParseCommits(lines)
+	commits, finishCommits := ParseCommits(lines, configFiles.ObjectFormat)
 	commits = SkipIgnored(commits, ignoreRevs)
 
 	finish := func() error {
@@ -151,14 +200,19 @@ func RevList(
 	return revs, nil
 }
 
-func GetRoot() (_ string, err error) {
+func GetRoot() (string, error) {
+	return GetRootContext(context.Background())
+}
+
+// Like GetRoot(), but runs Git with the given context.
+func GetRootContext(ctx context.Context) (_ string, err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("failed to get Git root directory: %w", err)
 		}
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	subprocess, err := cmd.RunRevParseTopLevel(ctx)
@@ -180,19 +234,38 @@ func GetRoot() (_ string, err error) {
 }
 
 // Returns all paths in the working tree under the given pathspecs.
-func WorkingTreeFiles(pathspecs []string) (_ map[string]bool, err error) {
+//
+// If recurseSubmodules is true, the paths of files in checked-out submodules
+// are returned instead of the paths of the submodules themselves.
+func WorkingTreeFiles(
+	pathspecs []string,
+	recurseSubmodules bool,
+) (map[string]bool, error) {
+	return WorkingTreeFilesContext(
+		context.Background(),
+		pathspecs,
+		recurseSubmodules,
+	)
+}
+
+// Like WorkingTreeFiles(), but runs Git with the given context.
+func WorkingTreeFilesContext(
+	ctx context.Context,
+	pathspecs []string,
+	recurseSubmodules bool,
+) (_ map[string]bool, err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("error getting tree files: %w", err)
 		}
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	wtreeset := map[string]bool{}
 
-	subprocess, err := cmd.RunLsFiles(ctx, pathspecs)
+	subprocess, err := cmd.RunLsFiles(ctx, pathspecs, recurseSubmodules)
 	if err != nil {
 		return wtreeset, err
 	}
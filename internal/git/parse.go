@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"iter"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -54,8 +55,94 @@ func allowCommit(commit Commit, now time.Time) bool {
 	return true
 }
 
-// Turns an iterator over lines from git log into an iterator of commits
-func ParseCommits(lines iter.Seq[string]) (iter.Seq[Commit], func() error) {
+// Parses a date output by git log with --date=raw, like "1735304504 -0500".
+// Returns the time and the UTC offset in seconds. The offset is optional.
+func parseRawTime(s string) (time.Time, int, error) {
+	secs, zone, hasZone := strings.Cut(s, " ")
+
+	i, err := strconv.Atoi(secs)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	offset := 0
+	if hasZone {
+		z, err := time.Parse("-0700", zone)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		_, offset = z.Zone()
+	}
+
+	return time.Unix(int64(i), 0), offset, nil
+}
+
+var trailerKeys = []string{
+	ReviewedByTrailer,
+	AckedByTrailer,
+	TestedByTrailer,
+	SignedOffByTrailer,
+}
+
+// Splits a value like "Name <email>" into the name and email. A value without
+// an email in angle brackets is taken to be just a name.
+func parseIdentity(value string) (name string, email string) {
+	value = strings.TrimSpace(value)
+	start := strings.LastIndex(value, "<")
+	if start >= 0 && strings.HasSuffix(value, ">") {
+		return strings.TrimSpace(value[:start]), value[start+1 : len(value)-1]
+	}
+
+	return value, ""
+}
+
+// Parses the trailers line output by git log. Each trailer looks like
+// "Key: Name <email>" and trailers are separated by the unit separator.
+func parseTrailers(line string) []Trailer {
+	if len(line) == 0 {
+		return nil
+	}
+
+	trailers := []Trailer{}
+	for _, s := range strings.Split(line, "\x1f") {
+		key, value, ok := strings.Cut(s, ":")
+		if !ok {
+			continue
+		}
+
+		// Git matches trailer keys case-insensitively
+		i := slices.IndexFunc(trailerKeys, func(k string) bool {
+			return strings.EqualFold(k, strings.TrimSpace(key))
+		})
+		if i < 0 {
+			continue
+		}
+
+		trailer := Trailer{Key: trailerKeys[i]}
+		trailer.Name, trailer.Email = parseIdentity(value)
+
+		if len(trailer.Name) == 0 && len(trailer.Email) == 0 {
+			continue
+		}
+
+		trailers = append(trailers, trailer)
+	}
+
+	return trailers
+}
+
+// Number of lines output by git log for each commit before the file diffs
+const numHeaderLines = 10
+
+// Turns an iterator over lines from git log into an iterator of commits.
+//
+// The object format of the repository is needed to tell where each commit
+// begins.
+func ParseCommits(
+	lines iter.Seq[string],
+	format rev.ObjectFormat,
+) (iter.Seq[Commit], func() error) {
 	var iterErr error
 
 	seq := func(yield func(Commit) bool) {
@@ -65,7 +152,8 @@ func ParseCommits(lines iter.Seq[string]) (iter.Seq[Commit], func() error) {
 		linesThisCommit := 0
 
 		for line := range lines {
-			done := linesThisCommit >= 6 && (len(line) == 0 || rev.IsFullHash(line))
+			done := linesThisCommit >= numHeaderLines &&
+				(len(line) == 0 || rev.IsFullHash(line, format))
 			if done {
 				if allowCommit(commit, now) {
 					if !yield(commit) {
@@ -95,7 +183,7 @@ func ParseCommits(lines iter.Seq[string]) (iter.Seq[Commit], func() error) {
 			case linesThisCommit == 4:
 				commit.AuthorEmail = line
 			case linesThisCommit == 5:
-				i, err := strconv.Atoi(line)
+				date, offset, err := parseRawTime(line)
 				if err != nil {
 					iterErr = fmt.Errorf(
 						"error parsing date from commit %s: %w",
@@ -105,7 +193,26 @@ func ParseCommits(lines iter.Seq[string]) (iter.Seq[Commit], func() error) {
 					return
 				}
 
-				commit.Date = time.Unix(int64(i), 0)
+				commit.Date = date
+				commit.AuthorOffset = offset
+			case linesThisCommit == 6:
+				commit.CommitterName = line
+			case linesThisCommit == 7:
+				commit.CommitterEmail = line
+			case linesThisCommit == 8:
+				date, _, err := parseRawTime(line)
+				if err != nil {
+					iterErr = fmt.Errorf(
+						"error parsing committer date from commit %s: %w",
+						commit.Name(),
+						err,
+					)
+					return
+				}
+
+				commit.CommitterDate = date
+			case linesThisCommit == 9:
+				commit.Trailers = parseTrailers(line)
 			default:
 				var err error
 
@@ -173,14 +280,14 @@ func ParseCommits(lines iter.Seq[string]) (iter.Seq[Commit], func() error) {
 						)
 					}
 				} else {
-					if len(diff.Path) > 0 {
+					// Renamed file. The path before the rename and the path
+					// after the rename each get their own line.
+					if len(diff.OldPath) > 0 {
 						diff.Path = line
 						commit.FileDiffs = append(commit.FileDiffs, *diff)
 						diff = nil
 					} else {
-						// Used to handle moved files specially. For now, just
-						// mark as path until we overwrite it with next line
-						diff.Path = line
+						diff.OldPath = line
 					}
 				}
 
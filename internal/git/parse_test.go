@@ -6,7 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/sinclairtarget/git-who/internal/git"
+	rev "github.com/sinclairtarget/git-who/internal/git/revision"
 )
 
 const fileRenameDump = `bf4136de996e9fb1f38620350cb7185613d71193
@@ -15,6 +18,10 @@ bf4136d
 Sinclair Target
 sinclairtarget@gmail.com
 1735304504
+Sinclair Target
+sinclairtarget@gmail.com
+1735304504
+
 9	0	file-rename/foo.go
 
 879e94bbbcbbec348ba1df332dd46e7314c62df1
@@ -23,6 +30,10 @@ bf4136d
 Sinclair Target
 sinclairtarget@gmail.com
 1735304522
+Sinclair Target
+sinclairtarget@gmail.com
+1735304522
+
 0	0
 file-rename/foo.go
 file-rename/bim.go
@@ -33,6 +44,10 @@ ad6d378
 Sinclair Target
 sinclairtarget@gmail.com
 1735304546
+Sinclair Target
+sinclairtarget@gmail.com
+1735304546
+
 1	1	file-rename/bim.go
 
 `
@@ -43,6 +58,10 @@ e4b688d
 Sinclair Target
 sinclairtarget@gmail.com
 1735487061
+Sinclair Target
+sinclairtarget@gmail.com
+1735487061
+
 1	0	rename-new-dir/hello.txt
 
 13b6f4f70c682ab06da9ef433cdb4fcbf65d78c3
@@ -51,6 +70,10 @@ sinclairtarget@gmail.com
 Sinclair Target
 sinclairtarget@gmail.com
 1735487089
+Sinclair Target
+sinclairtarget@gmail.com
+1735487089
+
 0	0
 rename-new-dir/hello.txt
 rename-new-dir/foo/hello.txt
@@ -63,6 +86,10 @@ const renameDeepDirDump = `5def9bfbddde001f6f324f2b781b6f2144bc3662
 Sinclair Target
 sinclairtarget@gmail.com
 1735507602
+Sinclair Target
+sinclairtarget@gmail.com
+1735507602
+
 1	0	rename-across-deep-dirs/foo/bar/hello.txt
 
 b9acb309a2c20ab6b93549bc7468b3e3ae5fc05e
@@ -71,20 +98,73 @@ b9acb30
 Sinclair Target
 sinclairtarget@gmail.com
 1735507662
+Sinclair Target
+sinclairtarget@gmail.com
+1735507662
+
 0	0
 rename-across-deep-dirs/foo/bar/hello.txt
 rename-across-deep-dirs/zim/zam/hello.txt
 
 `
 
+const rawDateDump = `6d47a1c0d483f90c00583fbb0a444fe9f4071a4b
+6d47a1c
+882a257
+Jane Doe
+jane@example.com
+1735304504 -0530
+Jim Maintainer
+jim@example.com
+1735304600 +0100
+
+2	1	src/foo.go
+
+`
+
+// A file renamed to a name that looks like a SHA-256 hash in a SHA-1 repo
+const hashLikeRenameDump = `6d47a1c0d483f90c00583fbb0a444fe9f4071a4b
+6d47a1c
+882a257
+Jane Doe
+jane@example.com
+1735304504
+Jim Maintainer
+jim@example.com
+1735304600
+
+0	0
+foo.txt
+3f0d9ca4a3b4c0a5f2e8f4b5f8b2d8a6c1e7d9f0a1b2c3d4e5f60718293a4b5c
+
+`
+
 func readDump(dump string) iter.Seq[string] {
 	return slices.Values(strings.Split(dump, "\n"))
 }
 
+const trailersDump = `6d47a1c0d483f90c00583fbb0a444fe9f4071a4b
+6d47a1c
+882a257
+Jane Doe
+jane@example.com
+1735304504
+Jim Maintainer
+jim@example.com
+1735304600
+` + "Reviewed-by: Rev One <rev1@example.com>\x1f" +
+	"acked-by: Ack Er <ack@example.com>\x1f" +
+	"Signed-off-by: Jane Doe <jane@example.com>\x1f" +
+	"Signed-off-by: Jim Maintainer <jim@example.com>\x1f" +
+	"Tested-by: Bot" + `
+2	1	src/foo.go
+
+`
+
 func TestParseFileRename(t *testing.T) {
 	lines := readDump(fileRenameDump)
 
-	seq, finish := git.ParseCommits(lines)
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
 	commits := slices.Collect(seq)
 	err := finish()
 	if err != nil {
@@ -119,13 +199,25 @@ func TestParseFileRename(t *testing.T) {
 			diff.Path,
 		)
 	}
+
+	if diff.OldPath != "file-rename/foo.go" {
+		t.Errorf(
+			"expected diff old path to be %s but got \"%s\"",
+			"file-rename/foo.go",
+			diff.OldPath,
+		)
+	}
+
+	if commits[2].FileDiffs[0].IsRename() {
+		t.Errorf("expected diff in last commit not to be a rename")
+	}
 }
 
 // Test moving a file into a new directory
 func TestCommitsFileRenameNewDir(t *testing.T) {
 	lines := readDump(renameNewDirDump)
 
-	seq, finish := git.ParseCommits(lines)
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
 	commits := slices.Collect(seq)
 	err := finish()
 	if err != nil {
@@ -166,7 +258,7 @@ func TestCommitsFileRenameNewDir(t *testing.T) {
 func TestCommitsRenameDeepDir(t *testing.T) {
 	lines := readDump(renameDeepDirDump)
 
-	seq, finish := git.ParseCommits(lines)
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
 	commits := slices.Collect(seq)
 	err := finish()
 	if err != nil {
@@ -201,4 +293,113 @@ func TestCommitsRenameDeepDir(t *testing.T) {
 			diff.Path,
 		)
 	}
+
+	if diff.OldPath != "rename-across-deep-dirs/foo/bar/hello.txt" {
+		t.Errorf(
+			"expected diff old path to be %s but got \"%s\"",
+			"rename-across-deep-dirs/foo/bar/hello.txt",
+			diff.OldPath,
+		)
+	}
+}
+
+func TestParseTrailers(t *testing.T) {
+	lines := readDump(trailersDump)
+
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
+	commits := slices.Collect(seq)
+	err := finish()
+	if err != nil {
+		t.Fatalf("error iterating commits: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit but found %d", len(commits))
+	}
+
+	expected := []git.Trailer{
+		{Key: git.ReviewedByTrailer, Name: "Rev One", Email: "rev1@example.com"},
+		{Key: git.AckedByTrailer, Name: "Ack Er", Email: "ack@example.com"},
+		{
+			Key:   git.SignedOffByTrailer,
+			Name:  "Jane Doe",
+			Email: "jane@example.com",
+		},
+		{
+			Key:   git.SignedOffByTrailer,
+			Name:  "Jim Maintainer",
+			Email: "jim@example.com",
+		},
+		{Key: git.TestedByTrailer, Name: "Bot"},
+	}
+	if diff := cmp.Diff(expected, commits[0].Trailers); diff != "" {
+		t.Errorf("trailers are wrong:\n%s", diff)
+	}
+
+	if len(commits[0].FileDiffs) != 1 {
+		t.Errorf(
+			"len of commit file diffs should be 1, but got %d",
+			len(commits[0].FileDiffs),
+		)
+	}
+}
+
+func TestParseRawDate(t *testing.T) {
+	lines := readDump(rawDateDump)
+
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
+	commits := slices.Collect(seq)
+	err := finish()
+	if err != nil {
+		t.Fatalf("error iterating commits: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit but found %d", len(commits))
+	}
+
+	commit := commits[0]
+	if commit.Date.Unix() != 1735304504 {
+		t.Errorf("expected author date 1735304504 but got %d", commit.Date.Unix())
+	}
+
+	expectedOffset := -(5*60 + 30) * 60
+	if commit.AuthorOffset != expectedOffset {
+		t.Errorf(
+			"expected author offset %d but got %d",
+			expectedOffset,
+			commit.AuthorOffset,
+		)
+	}
+
+	// 2024-12-27 13:01:44 UTC
+	local := commit.AuthorLocalDate()
+	if local.Hour() != 7 || local.Minute() != 31 {
+		t.Errorf("expected author-local time 07:31 but got %v", local)
+	}
+}
+
+func TestParseRenameToHashLikePath(t *testing.T) {
+	lines := readDump(hashLikeRenameDump)
+
+	seq, finish := git.ParseCommits(lines, rev.SHA1)
+	commits := slices.Collect(seq)
+	err := finish()
+	if err != nil {
+		t.Fatalf("error iterating commits: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit but found %d", len(commits))
+	}
+
+	expected := []git.FileDiff{
+		{
+			Path:    "3f0d9ca4a3b4c0a5f2e8f4b5f8b2d8a6c1e7d9f0a1b2c3d4e5f60718293a4b5c",
+			OldPath: "foo.txt",
+		},
+	}
+	if diff := cmp.Diff(expected, commits[0].FileDiffs); diff != "" {
+		t.Errorf("file diffs are wrong:\n%s", diff)
+	}
 }
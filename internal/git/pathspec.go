@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
 )
@@ -97,3 +98,136 @@ func PathspecMatch(pathspec string, p string) bool {
 
 	return false
 }
+
+// Turns pathspecs relative to a subdirectory of the repository into pathspecs
+// relative to the repository root.
+//
+// prefix is the path of the subdirectory relative to the root.
+func PathspecsFromRoot(pathspecs []string, prefix string) []string {
+	includes, excludes := SplitPathspecs(pathspecs)
+
+	fromRoot := []string{}
+	for _, p := range includes {
+		fromRoot = append(fromRoot, path.Join(prefix, p))
+	}
+
+	for _, p := range excludes {
+		fromRoot = append(fromRoot, ":(exclude)"+path.Join(prefix, p))
+	}
+
+	return fromRoot
+}
+
+// Translates a single (non-magic) pathspec relative to the superproject root
+// into one relative to the root of the submodule at the given path.
+//
+// Returns whole = true if the pathspec matches everything in the submodule and
+// ok = false if it cannot match anything in the submodule.
+func submodulePathspec(
+	pathspec string,
+	submodulePath string,
+) (translated string, whole bool, ok bool, err error) {
+	// Whether the directory d contains the submodule
+	containsSubmodule := func(d string) bool {
+		return d == "" || strings.HasPrefix(submodulePath+"/", d)
+	}
+
+	globIndex := strings.IndexAny(pathspec, "*?[")
+	if globIndex < 0 {
+		p := path.Clean(pathspec)
+		if p == "." || containsSubmodule(p+"/") {
+			return "", true, true, nil
+		}
+
+		if strings.HasPrefix(p, submodulePath+"/") {
+			return p[len(submodulePath)+1:], false, true, nil
+		}
+
+		return "", false, false, nil
+	}
+
+	dir := pathspec[:strings.LastIndex(pathspec[:globIndex], "/")+1]
+	rest := pathspec[len(dir):]
+
+	if containsSubmodule(dir) {
+		// A glob without a slash can match at any depth below dir, so it
+		// matches the same files when applied from the submodule root
+		if strings.Contains(rest, "/") {
+			return "", false, false, fmt.Errorf(
+				"pathspec \"%s\" cannot be applied to submodule \"%s\"",
+				pathspec,
+				submodulePath,
+			)
+		}
+
+		return rest, false, true, nil
+	}
+
+	if strings.HasPrefix(dir, submodulePath+"/") {
+		return pathspec[len(submodulePath)+1:], false, true, nil
+	}
+
+	return "", false, false, nil
+}
+
+// Translates pathspecs relative to the superproject root into pathspecs
+// relative to the root of the submodule at the given path.
+//
+// Returns false if the pathspecs exclude everything in the submodule.
+func SubmodulePathspecs(
+	pathspecs []string,
+	submodulePath string,
+) (_ []string, _ bool, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("could not translate pathspecs: %w", err)
+		}
+	}()
+
+	includes, excludes := SplitPathspecs(pathspecs)
+
+	translated := []string{}
+
+	if len(includes) > 0 {
+		matchedAny := false
+		matchedWhole := false
+
+		for _, p := range includes {
+			t, whole, ok, err := submodulePathspec(p, submodulePath)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if whole {
+				matchedWhole = true
+			} else if ok {
+				translated = append(translated, t)
+			}
+
+			matchedAny = matchedAny || ok
+		}
+
+		if !matchedAny {
+			return nil, false, nil
+		}
+
+		if matchedWhole {
+			translated = []string{}
+		}
+	}
+
+	for _, p := range excludes {
+		t, whole, ok, err := submodulePathspec(p, submodulePath)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if whole {
+			return nil, false, nil
+		} else if ok {
+			translated = append(translated, ":(exclude)"+t)
+		}
+	}
+
+	return translated, true, nil
+}
@@ -288,3 +288,161 @@ func TestPathspecMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestPathspecsFromRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		pathspecs []string
+		prefix    string
+		expected  []string
+	}{
+		{
+			name:      "root",
+			pathspecs: []string{"foo/", ":!*.txt"},
+			prefix:    "",
+			expected:  []string{"foo", ":(exclude)*.txt"},
+		},
+		{
+			name:      "subdir",
+			pathspecs: []string{"foo/", ":!*.txt"},
+			prefix:    "bar",
+			expected:  []string{"bar/foo", ":(exclude)bar/*.txt"},
+		},
+		{
+			name:      "dot",
+			pathspecs: []string{"."},
+			prefix:    "bar",
+			expected:  []string{"bar"},
+		},
+		{
+			name:      "parent",
+			pathspecs: []string{"../foo"},
+			prefix:    "bar",
+			expected:  []string{"foo"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := git.PathspecsFromRoot(test.pathspecs, test.prefix)
+			if diff := cmp.Diff(test.expected, result); diff != "" {
+				t.Errorf("pathspecs are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubmodulePathspecs(t *testing.T) {
+	tests := []struct {
+		name      string
+		pathspecs []string
+		expected  []string
+		ok        bool
+	}{
+		{
+			name:      "none",
+			pathspecs: []string{},
+			expected:  []string{},
+			ok:        true,
+		},
+		{
+			name:      "submodule",
+			pathspecs: []string{"libs/foo"},
+			expected:  []string{},
+			ok:        true,
+		},
+		{
+			name:      "parent",
+			pathspecs: []string{"libs/"},
+			expected:  []string{},
+			ok:        true,
+		},
+		{
+			name:      "inside",
+			pathspecs: []string{"libs/foo/src"},
+			expected:  []string{"src"},
+			ok:        true,
+		},
+		{
+			name:      "elsewhere",
+			pathspecs: []string{"libs/bar"},
+			ok:        false,
+		},
+		{
+			name:      "sibling_prefix",
+			pathspecs: []string{"libs/fo"},
+			ok:        false,
+		},
+		{
+			name:      "some_elsewhere",
+			pathspecs: []string{"libs/bar", "libs/foo/src"},
+			expected:  []string{"src"},
+			ok:        true,
+		},
+		{
+			name:      "glob",
+			pathspecs: []string{"*.c"},
+			expected:  []string{"*.c"},
+			ok:        true,
+		},
+		{
+			name:      "glob_in_parent",
+			pathspecs: []string{"libs/*.c"},
+			expected:  []string{"*.c"},
+			ok:        true,
+		},
+		{
+			name:      "glob_inside",
+			pathspecs: []string{"libs/foo/src/*.c"},
+			expected:  []string{"src/*.c"},
+			ok:        true,
+		},
+		{
+			name:      "exclude_inside",
+			pathspecs: []string{":!libs/foo/vendor"},
+			expected:  []string{":(exclude)vendor"},
+			ok:        true,
+		},
+		{
+			name:      "exclude_submodule",
+			pathspecs: []string{"libs/", ":!libs/foo"},
+			ok:        false,
+		},
+		{
+			name:      "exclude_elsewhere",
+			pathspecs: []string{":!libs/bar"},
+			expected:  []string{},
+			ok:        true,
+		},
+		{
+			name:      "exclude_glob",
+			pathspecs: []string{":!*.txt"},
+			expected:  []string{":(exclude)*.txt"},
+			ok:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok, err := git.SubmodulePathspecs(test.pathspecs, "libs/foo")
+			if err != nil {
+				t.Fatalf("SubmodulePathspecs() returned error: %v", err)
+			}
+
+			if ok != test.ok {
+				t.Fatalf("expected ok to be %v but got %v", test.ok, ok)
+			}
+
+			if diff := cmp.Diff(test.expected, result); diff != "" {
+				t.Errorf("pathspecs are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubmodulePathspecsUnsupported(t *testing.T) {
+	_, _, err := git.SubmodulePathspecs([]string{"libs/f*/src"}, "libs/foo")
+	if err == nil {
+		t.Errorf("expected error for glob spanning submodule boundary")
+	}
+}
@@ -2,6 +2,7 @@ package revision
 
 import (
 	"regexp"
+	"strings"
 )
 
 var commitHashRegexp *regexp.Regexp
@@ -10,10 +11,40 @@ func init() {
 	commitHashRegexp = regexp.MustCompile(`^\^?[a-f0-9]+$`)
 }
 
-// Returns true if this is a (full-length) Git revision hash, false otherwise.
+// The hash algorithm a repository uses to name its objects.
+type ObjectFormat string
+
+const (
+	SHA1   ObjectFormat = "sha1"
+	SHA256 ObjectFormat = "sha256"
+)
+
+// Returns the length of a full hash in this object format.
+func (f ObjectFormat) HashLen() int {
+	if f == SHA256 {
+		return 64
+	}
+
+	return 40
+}
+
+// Returns true if this is a (full-length) Git revision hash in a repository
+// using the given object format, false otherwise.
 //
 // We also need to handle a hash with "^" in front.
-func IsFullHash(s string) bool {
+func IsFullHash(s string, format ObjectFormat) bool {
+	if !commitHashRegexp.MatchString(s) {
+		return false
+	}
+
+	return len(strings.TrimPrefix(s, "^")) == format.HashLen()
+}
+
+// Returns true if this looks like an abbreviated Git revision hash in a
+// repository using the given object format.
+//
+// Git will not abbreviate a hash to fewer than four characters.
+func IsAbbrevHash(s string, format ObjectFormat) bool {
 	matched := commitHashRegexp.MatchString(s)
-	return matched && (len(s) == 40 || len(s) == 41)
+	return matched && s[0] != '^' && len(s) >= 4 && len(s) < format.HashLen()
 }
@@ -0,0 +1,75 @@
+package revision_test
+
+import (
+	"strings"
+	"testing"
+
+	rev "github.com/sinclairtarget/git-who/internal/git/revision"
+)
+
+const sha1Hash = "6afef287af5ca43f7d741e7ceff61aad38055b6a"
+const sha256Hash = "3f0d9ca4a3b4c0a5f2e8f4b5f8b2d8a6c1e7d9f0a1b2c3d4e5f60718293a4b5c"
+
+func TestIsFullHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		format   rev.ObjectFormat
+		expected bool
+	}{
+		{"sha1", sha1Hash, rev.SHA1, true},
+		{"sha1_caret", "^" + sha1Hash, rev.SHA1, true},
+		{"sha256", sha256Hash, rev.SHA256, true},
+		{"sha256_caret", "^" + sha256Hash, rev.SHA256, true},
+		{"sha256_in_sha1_repo", sha256Hash, rev.SHA1, false},
+		{"sha1_in_sha256_repo", sha1Hash, rev.SHA256, false},
+		{"abbrev", sha1Hash[:7], rev.SHA1, false},
+		{"in_between", sha256Hash[:50], rev.SHA256, false},
+		{"too_long", sha256Hash + "a", rev.SHA256, false},
+		{"not_hex", strings.Repeat("g", 40), rev.SHA1, false},
+		{"empty", "", rev.SHA1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rev.IsFullHash(test.s, test.format) != test.expected {
+				t.Errorf(
+					"expected IsFullHash(%q, %s) to be %v",
+					test.s,
+					test.format,
+					test.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestIsAbbrevHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		format   rev.ObjectFormat
+		expected bool
+	}{
+		{"sha1_abbrev", sha1Hash[:7], rev.SHA1, true},
+		{"sha1_full", sha1Hash, rev.SHA1, false},
+		{"sha1_too_short", sha1Hash[:3], rev.SHA1, false},
+		{"sha256_abbrev", sha256Hash[:7], rev.SHA256, true},
+		{"sha256_abbrev_sha1_length", sha256Hash[:40], rev.SHA256, true},
+		{"sha256_full", sha256Hash, rev.SHA256, false},
+		{"caret", "^" + sha1Hash[:7], rev.SHA1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rev.IsAbbrevHash(test.s, test.format) != test.expected {
+				t.Errorf(
+					"expected IsAbbrevHash(%q, %s) to be %v",
+					test.s,
+					test.format,
+					test.expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+)
+
+var (
+	// GitHub appends the number of the pull request to the subject, e.g.
+	// "Fix parser (#123)"
+	githubSquashSubject = regexp.MustCompile(`\(#\d+\)$`)
+	// GitLab can reference the merge request at the end of the message, e.g.
+	// "See merge request group/project!123"
+	gitlabMergeRequest = regexp.MustCompile(`^See merge request \S*!\d+$`)
+	// Both list the subjects of the squashed commits as bullets
+	squashedCommitBullet = regexp.MustCompile(`^\* \S`)
+	coAuthoredBy         = regexp.MustCompile(`(?i)^co-authored-by:(.*)$`)
+)
+
+// Returns the co-authors named in the message of each squash commit reachable
+// from the given revisions, keyed by the full hash of the squash commit. Squash
+// commits without any co-authors are left out, as are those not touching the
+// given paths or not passing the given filters.
+//
+// Co-authors are returned as written in the message. See ParseSquashMessage().
+func SquashCoAuthors(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+) (_ map[string][]Author, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error finding co-authors of squash commits: %w", err)
+		}
+	}()
+
+	subprocess, err := cmd.RunLogCoAuthored(ctx, revs, pathspecs, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := subprocess.StdoutText()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	coAuthors := map[string][]Author{}
+	if len(text) == 0 {
+		return coAuthors, nil
+	}
+
+	// Fields alternate between hash and message
+	fields := strings.Split(text, "\x00")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected number of fields: %d", len(fields))
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		hash := strings.TrimSpace(fields[i])
+
+		authors, isSquash := ParseSquashMessage(fields[i+1])
+		if isSquash && len(authors) > 0 {
+			coAuthors[hash] = authors
+		}
+	}
+
+	return coAuthors, nil
+}
+
+// Returns the co-authors named in a commit message and whether the message
+// looks like that of a squash commit made by GitHub or GitLab.
+//
+// A squash commit has a subject ending with the number of a GitHub pull
+// request, references a GitLab merge request, or lists two or more squashed
+// commits as bullets. Co-authors are taken from every Co-authored-by line in
+// the message, since GitHub keeps those of the squashed commits in the bullet
+// list as well as adding its own at the end.
+func ParseSquashMessage(message string) ([]Author, bool) {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+
+	isSquash := githubSquashSubject.MatchString(strings.TrimSpace(lines[0]))
+	numBullets := 0
+
+	authors := []Author{}
+	seen := map[string]bool{}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+
+		if gitlabMergeRequest.MatchString(line) {
+			isSquash = true
+		}
+
+		if squashedCommitBullet.MatchString(line) {
+			numBullets += 1
+		}
+
+		match := coAuthoredBy.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		var author Author
+		author.Name, author.Email = parseIdentity(match[1])
+		if len(author.Name) == 0 && len(author.Email) == 0 {
+			continue
+		}
+
+		key := strings.ToLower(author.Email)
+		if len(key) == 0 {
+			key = author.Name
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		authors = append(authors, author)
+	}
+
+	if numBullets >= 2 {
+		isSquash = true
+	}
+
+	return authors, isSquash
+}
@@ -0,0 +1,91 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+const githubSquashMessage = `Add parser (#42)
+
+* Start on parser
+
+* Handle errors
+
+Co-authored-by: Jim Pair <jim@example.com>
+
+* Fix typo
+
+---------
+
+Co-authored-by: Kim Dev <kim@example.com>
+co-authored-by: Jim Pair <JIM@example.com>
+`
+
+const gitlabSquashMessage = `Add parser
+
+Co-authored-by: Kim Dev <kim@example.com>
+
+See merge request group/project!7
+`
+
+const pairedMessage = `Add parser
+
+Co-authored-by: Kim Dev <kim@example.com>
+`
+
+func TestParseSquashMessage(t *testing.T) {
+	jim := git.Author{Name: "Jim Pair", Email: "jim@example.com"}
+	kim := git.Author{Name: "Kim Dev", Email: "kim@example.com"}
+
+	tests := []struct {
+		name             string
+		message          string
+		expectedAuthors  []git.Author
+		expectedIsSquash bool
+	}{
+		{
+			name:             "github",
+			message:          githubSquashMessage,
+			expectedAuthors:  []git.Author{jim, kim},
+			expectedIsSquash: true,
+		},
+		{
+			name:             "gitlab",
+			message:          gitlabSquashMessage,
+			expectedAuthors:  []git.Author{kim},
+			expectedIsSquash: true,
+		},
+		{
+			name:             "not_squash",
+			message:          pairedMessage,
+			expectedAuthors:  []git.Author{kim},
+			expectedIsSquash: false,
+		},
+		{
+			name:             "no_co_authors",
+			message:          "Fix bug (#3)\n",
+			expectedAuthors:  []git.Author{},
+			expectedIsSquash: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authors, isSquash := git.ParseSquashMessage(test.message)
+			if isSquash != test.expectedIsSquash {
+				t.Errorf(
+					"expected isSquash to be %v but got %v",
+					test.expectedIsSquash,
+					isSquash,
+				)
+			}
+
+			if diff := cmp.Diff(test.expectedAuthors, authors); diff != "" {
+				t.Errorf("co-authors are wrong:\n%s", diff)
+			}
+		})
+	}
+}
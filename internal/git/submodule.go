@@ -0,0 +1,170 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+)
+
+// Mode Git uses for a gitlink, the tree entry recording a submodule commit.
+const gitlinkMode = "160000"
+
+// A submodule as recorded in the commits of its superproject.
+type Submodule struct {
+	Path string // Relative to the root of the superproject
+	// Submodule revisions recorded by each of the superproject revisions we
+	// looked at. Excluded superproject revisions map to excluded submodule
+	// revisions.
+	Revs []string
+}
+
+// Returns the submodules recorded in the given superproject revisions.
+//
+// The revisions should be full hashes as returned by ParseArgs(), possibly
+// prefixed with "^". A submodule recorded only in excluded revisions is not
+// returned, since none of its history is included.
+func Submodules(ctx context.Context, revs []string) (_ []Submodule, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error listing submodules: %w", err)
+		}
+	}()
+
+	byPath := map[string]*Submodule{}
+	included := map[string]bool{}
+
+	for _, r := range revs {
+		excluded := strings.HasPrefix(r, "^")
+
+		gitlinks, err := lsGitlinks(ctx, strings.TrimPrefix(r, "^"))
+		if err != nil {
+			return nil, err
+		}
+
+		for p, hash := range gitlinks {
+			submodule, ok := byPath[p]
+			if !ok {
+				submodule = &Submodule{Path: p}
+				byPath[p] = submodule
+			}
+
+			if excluded {
+				submodule.Revs = append(submodule.Revs, "^"+hash)
+			} else {
+				submodule.Revs = append(submodule.Revs, hash)
+				included[p] = true
+			}
+		}
+	}
+
+	submodules := []Submodule{}
+	for p, submodule := range byPath {
+		if included[p] {
+			submodules = append(submodules, *submodule)
+		}
+	}
+
+	slices.SortFunc(submodules, func(a, b Submodule) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return submodules, nil
+}
+
+// Returns a map of path to commit hash for every gitlink in the tree of the
+// given revision.
+func lsGitlinks(ctx context.Context, r string) (map[string]string, error) {
+	subprocess, err := cmd.RunLsTree(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	gitlinks := map[string]string{}
+
+	lines, finish := subprocess.StdoutNullDelimitedLines()
+	for line := range lines {
+		// Each line looks like "<mode> <type> <hash>\t<path>"
+		meta, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) == 3 && fields[0] == gitlinkMode {
+			gitlinks[p] = fields[2]
+		}
+	}
+
+	err = finish()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return gitlinks, nil
+}
+
+// Returns the subset of the given revisions that name commits present in the
+// repository. Revisions may be prefixed with "^".
+//
+// A submodule that has been checked out may still be missing commits recorded
+// in older superproject commits if they were never fetched.
+func ExistingCommits(ctx context.Context, revs []string) (_ []string, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error checking for commits: %w", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subprocess, err := cmd.RunCatFileBatchCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(revs))
+	for i, r := range revs {
+		names[i] = strings.TrimPrefix(r, "^") + "^{commit}"
+	}
+	waitStdin := subprocess.WriteStdinLines(names)
+
+	existing := []string{}
+
+	// We get one line of output for each line of input
+	i := 0
+	lines, finish := subprocess.StdoutLines()
+	for line := range lines {
+		// Prints the full hash back for those that name a commit
+		if i < len(revs) && line == strings.TrimPrefix(revs[i], "^") {
+			existing = append(existing, revs[i])
+		}
+
+		i += 1
+	}
+
+	err = finish()
+	if err != nil {
+		return nil, err
+	}
+
+	err = waitStdin()
+	if err != nil {
+		return nil, err
+	}
+
+	err = subprocess.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
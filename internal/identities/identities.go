@@ -0,0 +1,338 @@
+// Finds identities in the commit history that likely belong to the same
+// person.
+package identities
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// A name and email pair as it appears in the commit history.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// identity -> number of commits
+type Counts map[Identity]int
+
+func (a Counts) Combine(b Counts) Counts {
+	for ident, n := range b {
+		a[ident] += n
+	}
+
+	return a
+}
+
+// Counts commits for each identity credited according to the identity mode.
+//
+// Only authors count toward the number of commits for an identity. Other
+// identities are recorded with a count of zero so that they can still be
+// clustered.
+func CountCommits(
+	commits iter.Seq[git.Commit],
+	mode tally.IdentityMode,
+) Counts {
+	counts := Counts{}
+
+	for commit := range commits {
+		author := Identity{commit.AuthorName, commit.AuthorEmail}
+		committer := Identity{commit.CommitterName, commit.CommitterEmail}
+
+		switch mode {
+		case tally.AuthorIdentity:
+			counts[author] += 1
+		case tally.CommitterIdentity:
+			counts[committer] += 0
+		case tally.AuthorOrCommitterIdentity:
+			counts[author] += 1
+			counts[committer] += 0
+		case tally.ReviewerIdentity:
+			for _, trailer := range commit.Trailers {
+				counts[Identity{trailer.Name, trailer.Email}] += 0
+			}
+		default:
+			panic("unrecognized identity mode in switch")
+		}
+	}
+
+	return counts
+}
+
+type Member struct {
+	Identity
+	Commits int
+}
+
+// Identities that likely belong to the same person, ordered by number of
+// commits. The first member is the canonical identity for the cluster.
+type Cluster []Member
+
+func (c Cluster) Canonical() Identity {
+	return c[0].Identity
+}
+
+func (c Cluster) Commits() int {
+	total := 0
+	for _, m := range c {
+		total += m.Commits
+	}
+
+	return total
+}
+
+// Groups identities into clusters of identities that likely belong to the same
+// person.
+//
+// Two identities are clustered together if they share an email address, a
+// name, or the part of their email address before the "@" at the same domain.
+// Names and email addresses are compared ignoring case, diacritics, and
+// whitespace, and the username in a GitHub noreply address counts as the part
+// before the "@".
+//
+// The part before the "@" is only compared within a domain because different
+// people often have the same one at different domains, e.g. "jsmith".
+//
+// Clusters are ordered by number of commits. Identities that match no other
+// identity are returned as clusters of one.
+func Clusters(counts Counts) []Cluster {
+	idents := []Identity{}
+	for ident := range counts {
+		idents = append(idents, ident)
+	}
+
+	// Sort first so that clustering does not depend on map order
+	slices.SortFunc(idents, func(a, b Identity) int {
+		return cmp.Or(
+			cmp.Compare(counts[b], counts[a]),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email),
+		)
+	})
+
+	parents := make([]int, len(idents))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	firstWithKey := map[string]int{}
+	for i, ident := range idents {
+		for _, key := range matchKeys(ident) {
+			j, ok := firstWithKey[key]
+			if !ok {
+				firstWithKey[key] = i
+				continue
+			}
+
+			// Lower index always becomes the root, so the root of each
+			// cluster is the identity with the most commits
+			a, b := find(i), find(j)
+			parents[max(a, b)] = min(a, b)
+		}
+	}
+
+	byRoot := map[int]Cluster{}
+	for i, ident := range idents {
+		root := find(i)
+		byRoot[root] = append(byRoot[root], Member{ident, counts[ident]})
+	}
+
+	clusters := []Cluster{}
+	for _, cluster := range byRoot {
+		clusters = append(clusters, cluster)
+	}
+
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return cmp.Or(
+			cmp.Compare(b.Commits(), a.Commits()),
+			cmp.Compare(a.Canonical().Name, b.Canonical().Name),
+			cmp.Compare(a.Canonical().Email, b.Canonical().Email),
+		)
+	})
+
+	return clusters
+}
+
+// Returns a function mapping each identity in the clusters to the canonical
+// identity of its cluster. Identities not in any cluster are returned as is.
+func Merger(clusters []Cluster) func(name, email string) (string, string) {
+	canonical := map[Identity]Identity{}
+	for _, cluster := range clusters {
+		for _, m := range cluster {
+			canonical[m.Identity] = cluster.Canonical()
+		}
+	}
+
+	return func(name, email string) (string, string) {
+		ident, ok := canonical[Identity{name, email}]
+		if !ok {
+			return name, email
+		}
+
+		return ident.Name, ident.Email
+	}
+}
+
+// Returns .mailmap entries mapping every identity in the clusters to the
+// canonical identity of its cluster.
+func MailmapEntries(clusters []Cluster) []string {
+	entries := []string{}
+	for _, cluster := range clusters {
+		canonical := cluster.Canonical()
+		for _, m := range cluster[1:] {
+			entries = append(
+				entries,
+				fmt.Sprintf("%s %s", canonical, m.Identity),
+			)
+		}
+	}
+
+	return entries
+}
+
+// Local parts too generic to say anything about who made a commit.
+var genericUsers = map[string]bool{
+	"admin":    true,
+	"dev":      true,
+	"git":      true,
+	"github":   true,
+	"info":     true,
+	"mail":     true,
+	"me":       true,
+	"no-reply": true,
+	"noreply":  true,
+	"root":     true,
+	"test":     true,
+	"user":     true,
+}
+
+// Names too generic to say anything about who made a commit, mostly the
+// defaults of machines, tools, and tutorials. Compared after normalize().
+var genericNames = map[string]bool{
+	"admin":          true,
+	"administrator":  true,
+	"git":            true,
+	"github":         true,
+	"github action":  true,
+	"github actions": true,
+	"root":           true,
+	"test":           true,
+	"ubuntu":         true,
+	"unknown":        true,
+	"user":           true,
+	"your name":      true,
+}
+
+// Keys shared by identities we consider the same person.
+func matchKeys(ident Identity) []string {
+	keys := []string{}
+
+	// Bots like "dependabot[bot]" commit on behalf of many people
+	name := normalize(ident.Name)
+	isBot := strings.HasSuffix(name, "[bot]")
+	if len(name) > 0 && !genericNames[name] && !isBot {
+		keys = append(keys, "name:"+name)
+	}
+
+	email := normalize(ident.Email)
+	if len(email) > 0 {
+		keys = append(keys, "email:"+email)
+	}
+
+	user, domain := emailUser(email)
+	if len(user) > 0 && !genericUsers[user] {
+		keys = append(keys, "user:"+user+"@"+domain)
+	}
+
+	return keys
+}
+
+// Returns the part of a normalized email address identifying the user, along
+// with the domain.
+//
+// For GitHub noreply addresses, like "123+octocat@users.noreply.github.com",
+// this is the GitHub username. Otherwise it is the part before the "@" minus
+// any "+" suffix.
+func emailUser(email string) (user string, domain string) {
+	user, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "", ""
+	}
+
+	if domain == "users.noreply.github.com" {
+		_, username, ok := strings.Cut(user, "+")
+		if ok {
+			return username, domain
+		}
+
+		return user, domain
+	}
+
+	user, _, _ = strings.Cut(user, "+")
+	return user, domain
+}
+
+// Lowercases the string, strips diacritics, and collapses whitespace.
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if folded, ok := diacritics[r]; ok {
+			return folded
+		}
+
+		return unicode.ToLower(r)
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Letters with diacritics mapped to the lowercase letter without them.
+var diacritics = map[rune]rune{}
+
+func init() {
+	folds := map[rune]string{
+		'a': "àáâãäåāăąÀÁÂÃÄÅĀĂĄ",
+		'c': "çćĉċčÇĆĈĊČ",
+		'd': "ďđĎĐ",
+		'e': "èéêëēĕėęěÈÉÊËĒĔĖĘĚ",
+		'g': "ĝğġģĜĞĠĢ",
+		'h': "ĥħĤĦ",
+		'i': "ìíîïĩīĭįıÌÍÎÏĨĪĬĮİ",
+		'j': "ĵĴ",
+		'k': "ķĶ",
+		'l': "ĺļľŀłĹĻĽĿŁ",
+		'n': "ñńņňÑŃŅŇ",
+		'o': "òóôõöøōŏőÒÓÔÕÖØŌŎŐ",
+		'r': "ŕŗřŔŖŘ",
+		's': "śŝşšșŚŜŞŠȘ",
+		't': "ţťŧțŢŤŦȚ",
+		'u': "ùúûüũūŭůűųÙÚÛÜŨŪŬŮŰŲ",
+		'w': "ŵŴ",
+		'y': "ýÿŷÝŸŶ",
+		'z': "źżžŹŻŽ",
+	}
+
+	for base, letters := range folds {
+		for _, r := range letters {
+			diacritics[r] = base
+		}
+	}
+}
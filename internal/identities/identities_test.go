@@ -0,0 +1,229 @@
+package identities_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/identities"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   identities.Counts
+		expected [][]string
+	}{
+		{
+			name: "same_email_user",
+			counts: identities.Counts{
+				{"Jane Doe", "jane@work.com"}:   3,
+				{"J. Doe", "jane+git@work.com"}: 1,
+			},
+			expected: [][]string{
+				{"Jane Doe <jane@work.com>", "J. Doe <jane+git@work.com>"},
+			},
+		},
+		{
+			name: "same_email_user_other_domain",
+			counts: identities.Counts{
+				{"Jane Smith", "jsmith@work.com"}: 3,
+				{"John Smith", "jsmith@home.net"}: 1,
+			},
+			expected: [][]string{
+				{"Jane Smith <jsmith@work.com>"},
+				{"John Smith <jsmith@home.net>"},
+			},
+		},
+		{
+			name: "same_name",
+			counts: identities.Counts{
+				{"Jane Doe", "jane@work.com"}: 3,
+				{"Jane Doe", "jdoe@home.net"}: 1,
+			},
+			expected: [][]string{
+				{"Jane Doe <jane@work.com>", "Jane Doe <jdoe@home.net>"},
+			},
+		},
+		{
+			name: "case_diacritics_whitespace",
+			counts: identities.Counts{
+				{"José Álvarez", "jose@work.com"}:   1,
+				{"jose  alvarez", "jalvarez@b.com"}: 2,
+			},
+			expected: [][]string{
+				{"jose  alvarez <jalvarez@b.com>", "José Álvarez <jose@work.com>"},
+			},
+		},
+		{
+			name: "github_noreply",
+			counts: identities.Counts{
+				{"Jane Doe", "jdoe@users.noreply.github.com"}:        2,
+				{"octo", "123+jdoe@users.noreply.github.com"}:        1,
+				{"someone", "someone@users.noreply.github.com"}:      1,
+				{"someone else", "SOMEONE@users.noreply.github.com"}: 1,
+			},
+			expected: [][]string{
+				{
+					"Jane Doe <jdoe@users.noreply.github.com>",
+					"octo <123+jdoe@users.noreply.github.com>",
+				},
+				{
+					"someone <someone@users.noreply.github.com>",
+					"someone else <SOMEONE@users.noreply.github.com>",
+				},
+			},
+		},
+		{
+			name: "transitive",
+			counts: identities.Counts{
+				{"Jane Doe", "jane@work.com"}: 3,
+				{"Jane Doe", "jd@home.net"}:   2,
+				{"JD", "jd+oss@home.net"}:     1,
+			},
+			expected: [][]string{
+				{
+					"Jane Doe <jane@work.com>",
+					"Jane Doe <jd@home.net>",
+					"JD <jd+oss@home.net>",
+				},
+			},
+		},
+		{
+			name: "generic_user",
+			counts: identities.Counts{
+				{"Bob", "root@work.com"}: 2,
+				{"Sue", "root@home.net"}: 1,
+			},
+			expected: [][]string{
+				{"Bob <root@work.com>"},
+				{"Sue <root@home.net>"},
+			},
+		},
+		{
+			name: "generic_name",
+			counts: identities.Counts{
+				{"root", "alice@work.com"}: 2,
+				{"root", "bob@home.net"}:   1,
+			},
+			expected: [][]string{
+				{"root <alice@work.com>"},
+				{"root <bob@home.net>"},
+			},
+		},
+		{
+			name: "bot_name",
+			counts: identities.Counts{
+				{"dependabot[bot]", "deps@work.com"}: 2,
+				{"dependabot[bot]", "deps@home.net"}: 1,
+			},
+			expected: [][]string{
+				{"dependabot[bot] <deps@work.com>"},
+				{"dependabot[bot] <deps@home.net>"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clusters := [][]string{}
+			for _, cluster := range identities.Clusters(test.counts) {
+				members := []string{}
+				for _, m := range cluster {
+					members = append(members, m.String())
+				}
+				clusters = append(clusters, members)
+			}
+
+			if diff := cmp.Diff(test.expected, clusters); diff != "" {
+				t.Errorf("clusters are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMerger(t *testing.T) {
+	clusters := identities.Clusters(identities.Counts{
+		{"Jane Doe", "jane@work.com"}: 3,
+		{"jane doe", "jd@home.net"}:   1,
+	})
+	merge := identities.Merger(clusters)
+
+	name, email := merge("jane doe", "jd@home.net")
+	if name != "Jane Doe" || email != "jane@work.com" {
+		t.Errorf("expected identity to be merged, got %s <%s>", name, email)
+	}
+
+	name, email = merge("Bob", "bob@mail.com")
+	if name != "Bob" || email != "bob@mail.com" {
+		t.Errorf("expected unknown identity unchanged, got %s <%s>", name, email)
+	}
+}
+
+func TestMailmapEntries(t *testing.T) {
+	clusters := identities.Clusters(identities.Counts{
+		{"Jane Doe", "jane@work.com"}: 3,
+		{"jane doe", "jd@home.net"}:   1,
+		{"Bob", "bob@mail.com"}:       1,
+	})
+
+	expected := []string{
+		"Jane Doe <jane@work.com> jane doe <jd@home.net>",
+	}
+	entries := identities.MailmapEntries(clusters)
+	if diff := cmp.Diff(expected, entries); diff != "" {
+		t.Errorf("mailmap entries are wrong:\n%s", diff)
+	}
+}
+
+func TestCountCommits(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			AuthorName:     "bob",
+			AuthorEmail:    "bob@mail.com",
+			CommitterName:  "jim",
+			CommitterEmail: "jim@mail.com",
+		},
+		git.Commit{
+			AuthorName:     "bob",
+			AuthorEmail:    "bob@mail.com",
+			CommitterName:  "bob",
+			CommitterEmail: "bob@mail.com",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		identity tally.IdentityMode
+		expected identities.Counts
+	}{
+		{
+			name:     "author",
+			identity: tally.AuthorIdentity,
+			expected: identities.Counts{{"bob", "bob@mail.com"}: 2},
+		},
+		{
+			name:     "author_or_committer",
+			identity: tally.AuthorOrCommitterIdentity,
+			expected: identities.Counts{
+				{"bob", "bob@mail.com"}: 2,
+				{"jim", "jim@mail.com"}: 0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counts := identities.CountCommits(
+				slices.Values(commits),
+				test.identity,
+			)
+			if diff := cmp.Diff(test.expected, counts); diff != "" {
+				t.Errorf("counts are wrong:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Classifies files by the language they are written in.
+package langs
+
+import (
+	"path"
+	"strings"
+)
+
+// Files that match no known language.
+const Other = "Other"
+
+// Well-known filenames, matched ignoring case.
+var byFilename = map[string]string{
+	"makefile":       "Makefile",
+	"gnumakefile":    "Makefile",
+	"dockerfile":     "Dockerfile",
+	"containerfile":  "Dockerfile",
+	"cmakelists.txt": "CMake",
+	"gemfile":        "Ruby",
+	"rakefile":       "Ruby",
+	"podfile":        "Ruby",
+	"vagrantfile":    "Ruby",
+	"jenkinsfile":    "Groovy",
+	"build.gradle":   "Groovy",
+	"go.mod":         "Go Module",
+	"go.sum":         "Go Module",
+	"cargo.lock":     "TOML",
+	".bashrc":        "Shell",
+	".zshrc":         "Shell",
+	".profile":       "Shell",
+	".gitignore":     "Ignore List",
+	".dockerignore":  "Ignore List",
+	".gitattributes": "Git Attributes",
+	".gitmodules":    "Git Config",
+	".mailmap":       "Git Mailmap",
+	"license":        "Text",
+	"copying":        "Text",
+	"readme":         "Text",
+}
+
+// File extensions, matched ignoring case.
+var byExtension = map[string]string{
+	".bash":     "Shell",
+	".bat":      "Batchfile",
+	".c":        "C",
+	".cc":       "C++",
+	".cjs":      "JavaScript",
+	".clj":      "Clojure",
+	".cljs":     "Clojure",
+	".cmake":    "CMake",
+	".cpp":      "C++",
+	".cs":       "C#",
+	".css":      "CSS",
+	".cxx":      "C++",
+	".dart":     "Dart",
+	".erl":      "Erlang",
+	".ex":       "Elixir",
+	".exs":      "Elixir",
+	".fish":     "Shell",
+	".fs":       "F#",
+	".go":       "Go",
+	".gradle":   "Groovy",
+	".graphql":  "GraphQL",
+	".groovy":   "Groovy",
+	".h":        "C",
+	".hh":       "C++",
+	".hpp":      "C++",
+	".hrl":      "Erlang",
+	".hs":       "Haskell",
+	".htm":      "HTML",
+	".html":     "HTML",
+	".ini":      "INI",
+	".ipynb":    "Jupyter Notebook",
+	".java":     "Java",
+	".jl":       "Julia",
+	".js":       "JavaScript",
+	".json":     "JSON",
+	".jsx":      "JavaScript",
+	".kt":       "Kotlin",
+	".kts":      "Kotlin",
+	".less":     "Less",
+	".lua":      "Lua",
+	".m":        "Objective-C",
+	".markdown": "Markdown",
+	".md":       "Markdown",
+	".mjs":      "JavaScript",
+	".mk":       "Makefile",
+	".ml":       "OCaml",
+	".mli":      "OCaml",
+	".mm":       "Objective-C++",
+	".nix":      "Nix",
+	".php":      "PHP",
+	".pl":       "Perl",
+	".pm":       "Perl",
+	".proto":    "Protocol Buffers",
+	".ps1":      "PowerShell",
+	".py":       "Python",
+	".pyi":      "Python",
+	".r":        "R",
+	".rb":       "Ruby",
+	".rs":       "Rust",
+	".rst":      "reStructuredText",
+	".sass":     "Sass",
+	".scala":    "Scala",
+	".scss":     "SCSS",
+	".sh":       "Shell",
+	".sql":      "SQL",
+	".svelte":   "Svelte",
+	".swift":    "Swift",
+	".tf":       "HCL",
+	".toml":     "TOML",
+	".ts":       "TypeScript",
+	".tsx":      "TypeScript",
+	".txt":      "Text",
+	".vue":      "Vue",
+	".xml":      "XML",
+	".yaml":     "YAML",
+	".yml":      "YAML",
+	".zig":      "Zig",
+	".zsh":      "Shell",
+}
+
+// Returns the language of the file at the given slash-separated path, or
+// Other if it isn't one we know.
+func Classify(p string) string {
+	base := strings.ToLower(path.Base(p))
+	if lang, ok := byFilename[base]; ok {
+		return lang
+	}
+
+	if lang, ok := byExtension[path.Ext(base)]; ok {
+		return lang
+	}
+
+	return Other
+}
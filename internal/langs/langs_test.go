@@ -0,0 +1,36 @@
+package langs_test
+
+import (
+	"testing"
+
+	"github.com/sinclairtarget/git-who/internal/langs"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"main.go", "Go"},
+		{"internal/git/git.go", "Go"},
+		{"web/App.TSX", "TypeScript"},
+		{"scripts/build.sh", "Shell"},
+		{"Makefile", "Makefile"},
+		{"docker/Dockerfile", "Dockerfile"},
+		{"go.mod", "Go Module"},
+		{"CMakeLists.txt", "CMake"},
+		{"notes.txt", "Text"},
+		{".gitignore", "Ignore List"},
+		{"logo.png", langs.Other},
+		{"bin/run", langs.Other},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			lang := langs.Classify(test.path)
+			if lang != test.expected {
+				t.Errorf("expected %q but got %q", test.expected, lang)
+			}
+		})
+	}
+}
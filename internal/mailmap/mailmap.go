@@ -0,0 +1,354 @@
+// Parses and checks mailmap files.
+//
+// See gitmailmap(5) for the format.
+package mailmap
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/identities"
+)
+
+// A single line mapping a commit identity to a proper identity.
+type Entry struct {
+	File        string
+	Line        int
+	ProperName  string // May be empty
+	ProperEmail string // May be empty
+	CommitName  string // May be empty, meaning match any name
+	CommitEmail string
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+
+	if len(e.ProperName) > 0 {
+		b.WriteString(e.ProperName + " ")
+	}
+	if len(e.ProperEmail) > 0 {
+		b.WriteString("<" + e.ProperEmail + "> ")
+	}
+	if len(e.CommitName) > 0 {
+		b.WriteString(e.CommitName + " ")
+	}
+	b.WriteString("<" + e.CommitEmail + ">")
+
+	return b.String()
+}
+
+// A line in a mailmap file that could not be parsed.
+type ParseError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Entries from one or more mailmap files. Later entries take precedence.
+type Mailmap []Entry
+
+// Parses a mailmap, returning the entries that could be parsed and an error for
+// each line that could not.
+func Parse(r io.Reader, file string) (Mailmap, []ParseError, error) {
+	entries := Mailmap{}
+	parseErrs := []ParseError{}
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo += 1
+
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, err := parseLine(line)
+		if err != nil {
+			parseErrs = append(parseErrs, ParseError{file, lineNo, err.Error()})
+			continue
+		}
+
+		entry.File = file
+		entry.Line = lineNo
+		entries = append(entries, entry)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, parseErrs, nil
+}
+
+// Reads and parses the mailmap files at the given paths, in order.
+func ReadFiles(paths ...string) (Mailmap, []ParseError, error) {
+	entries := Mailmap{}
+	parseErrs := []ParseError{}
+
+	for _, p := range paths {
+		err := func() error {
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			fileEntries, fileErrs, err := Parse(f, p)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, fileEntries...)
+			parseErrs = append(parseErrs, fileErrs...)
+			return nil
+		}()
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read mailmap: %w", err)
+		}
+	}
+
+	return entries, parseErrs, nil
+}
+
+func parseLine(line string) (Entry, error) {
+	var entry Entry
+
+	names := []string{}
+	emails := []string{}
+
+	rest := line
+	for {
+		start := strings.IndexAny(rest, "<>")
+		if start < 0 {
+			break
+		}
+		if rest[start] == '>' {
+			return entry, errors.New("unexpected \">\"")
+		}
+
+		end := strings.IndexAny(rest[start+1:], "<>")
+		if end < 0 || rest[start+1+end] == '<' {
+			return entry, errors.New("unclosed \"<\"")
+		}
+		end += start + 1
+
+		names = append(names, strings.Join(strings.Fields(rest[:start]), " "))
+		emails = append(emails, strings.TrimSpace(rest[start+1:end]))
+		rest = rest[end+1:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) > 0 && !strings.HasPrefix(rest, "#") {
+		return entry, fmt.Errorf("unexpected text after email: %q", rest)
+	}
+
+	switch len(emails) {
+	case 0:
+		return entry, errors.New("no email address")
+	case 1:
+		if len(names[0]) == 0 {
+			return entry, errors.New("entry has no proper name or email")
+		}
+
+		entry.ProperName = names[0]
+		entry.CommitEmail = emails[0]
+	case 2:
+		entry.ProperName = names[0]
+		entry.ProperEmail = emails[0]
+		entry.CommitName = names[1]
+		entry.CommitEmail = emails[1]
+	default:
+		return entry, errors.New("more than two email addresses")
+	}
+
+	return entry, nil
+}
+
+// Maps a commit identity to its proper identity the way Git does.
+//
+// Entries matching both the name and the email take precedence over entries
+// matching only the email. Like Git, we compare both names and emails ignoring
+// case, but only for ASCII letters. Also returns the indices of the entries
+// applied.
+func (m Mailmap) Map(name, email string) (string, string, []int) {
+	nameMatches := []int{}
+	emailMatches := []int{}
+
+	for i, entry := range m {
+		if !asciiEqualFold(entry.CommitEmail, email) {
+			continue
+		}
+
+		if len(entry.CommitName) == 0 {
+			emailMatches = append(emailMatches, i)
+		} else if asciiEqualFold(entry.CommitName, name) {
+			nameMatches = append(nameMatches, i)
+		}
+	}
+
+	matches := emailMatches
+	if len(nameMatches) > 0 {
+		matches = nameMatches
+	}
+
+	// Later entries override the fields they set
+	for _, i := range matches {
+		if len(m[i].ProperName) > 0 {
+			name = m[i].ProperName
+		}
+		if len(m[i].ProperEmail) > 0 {
+			email = m[i].ProperEmail
+		}
+	}
+
+	return name, email, matches
+}
+
+// Maps each of the given authors, dropping any that map to an author listed
+// before them.
+//
+// Git applies the mailmap to commit authors but not to the co-authors named in
+// commit messages, so we map those ourselves.
+func (m Mailmap) MapAuthors(authors []git.Author) []git.Author {
+	mapped := []git.Author{}
+	seen := map[string]bool{}
+
+	for _, author := range authors {
+		author.Name, author.Email, _ = m.Map(author.Name, author.Email)
+
+		key := strings.ToLower(author.Email)
+		if len(key) == 0 {
+			key = author.Name
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		mapped = append(mapped, author)
+	}
+
+	return mapped
+}
+
+// Reports whether the strings are equal ignoring the case of ASCII letters,
+// like strcasecmp(3) in the C locale, which is what Git uses.
+func asciiEqualFold(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := 0; i < len(a); i++ {
+		if asciiLower(a[i]) != asciiLower(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func asciiLower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+
+	return c
+}
+
+// Problems found checking a mailmap against the commit history.
+type Report struct {
+	ParseErrors []ParseError
+	Commits     []int   // Number of commits matched by each entry
+	Unused      []Entry // Entries matching no identity in the history
+	// Groups of raw identities that look like the same person, see
+	// identities.Clusters(), but that the mailmap leaves apart because some of
+	// them match no entry
+	Unmerged [][]identities.Identity
+}
+
+func (r Report) NumProblems() int {
+	return len(r.ParseErrors) + len(r.Unused) + len(r.Unmerged)
+}
+
+// Checks the mailmap against the raw identities found in the commit history.
+func Check(
+	m Mailmap,
+	parseErrs []ParseError,
+	counts identities.Counts,
+) Report {
+	report := Report{
+		ParseErrors: parseErrs,
+		Commits:     make([]int, len(m)),
+		Unused:      []Entry{},
+		Unmerged:    [][]identities.Identity{},
+	}
+
+	used := make([]bool, len(m))
+	for ident, n := range counts {
+		_, _, matches := m.Map(ident.Name, ident.Email)
+		for _, i := range matches {
+			used[i] = true
+			report.Commits[i] += n
+		}
+	}
+
+	for i, entry := range m {
+		if !used[i] {
+			report.Unused = append(report.Unused, entry)
+		}
+	}
+
+	for _, cluster := range identities.Clusters(counts) {
+		if !isMerged(m, cluster) {
+			group := []identities.Identity{}
+			for _, member := range cluster {
+				group = append(group, member.Identity)
+			}
+			slices.SortFunc(group, func(a, b identities.Identity) int {
+				return cmp.Or(
+					cmp.Compare(a.Email, b.Email),
+					cmp.Compare(a.Name, b.Name),
+				)
+			})
+
+			report.Unmerged = append(report.Unmerged, group)
+		}
+	}
+	slices.SortFunc(report.Unmerged, func(a, b []identities.Identity) int {
+		return cmp.Compare(a[0].Name, b[0].Name)
+	})
+
+	return report
+}
+
+// Whether the identities in the cluster end up under one name once mapped, as
+// long as some of them match no entry. We tell authors apart by name unless
+// asked to use emails, so an entry that only fixes the name is enough, and
+// clusters where every identity matches an entry are left to the mailmap.
+func isMerged(m Mailmap, cluster identities.Cluster) bool {
+	names := map[string]bool{}
+	hasUnmatched := false
+	for _, member := range cluster {
+		name, _, matches := m.Map(member.Name, member.Email)
+		if len(matches) == 0 {
+			hasUnmatched = true
+		}
+
+		names[name] = true
+	}
+
+	return !hasUnmatched || len(names) < 2
+}
@@ -0,0 +1,211 @@
+package mailmap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/identities"
+	"github.com/sinclairtarget/git-who/internal/mailmap"
+)
+
+func ident(name, email string) identities.Identity {
+	return identities.Identity{Name: name, Email: email}
+}
+
+func TestParse(t *testing.T) {
+	input := strings.TrimSpace(`
+# A comment
+Jane Doe <jane@work.com>
+<jane@work.com> <jane@home.net>
+Jane Doe <jane@work.com> jdoe <JDOE@home.net> # trailing comment
+
+Bob <bob@work.com
+<bob@work.com>
+Bob <a@b.com> <c@d.com> <e@f.com>
+Bob bob@work.com
+Bob <bob@work.com> extra
+	`)
+
+	entries, parseErrs, err := mailmap.Parse(strings.NewReader(input), "m")
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	expectedEntries := mailmap.Mailmap{
+		{
+			File:        "m",
+			Line:        2,
+			ProperName:  "Jane Doe",
+			CommitEmail: "jane@work.com",
+		},
+		{
+			File:        "m",
+			Line:        3,
+			ProperEmail: "jane@work.com",
+			CommitEmail: "jane@home.net",
+		},
+		{
+			File:        "m",
+			Line:        4,
+			ProperName:  "Jane Doe",
+			ProperEmail: "jane@work.com",
+			CommitName:  "jdoe",
+			CommitEmail: "JDOE@home.net",
+		},
+	}
+	if diff := cmp.Diff(expectedEntries, entries); diff != "" {
+		t.Errorf("entries are wrong:\n%s", diff)
+	}
+
+	expectedLines := []int{6, 7, 8, 9, 10}
+	lines := []int{}
+	for _, parseErr := range parseErrs {
+		lines = append(lines, parseErr.Line)
+	}
+	if diff := cmp.Diff(expectedLines, lines); diff != "" {
+		t.Errorf("malformed lines are wrong:\n%s", diff)
+	}
+}
+
+func TestMap(t *testing.T) {
+	m := mailmap.Mailmap{
+		{ProperName: "Jane Doe", CommitEmail: "jane@work.com"},
+		{ProperEmail: "jane@work.com", CommitEmail: "jane@home.net"},
+		{ProperName: "Jane Doe", CommitEmail: "jane@home.net"},
+		{
+			ProperName:  "Bob",
+			ProperEmail: "bob@work.com",
+			CommitName:  "bobby",
+			CommitEmail: "shared@work.com",
+		},
+		{
+			ProperName:  "José",
+			ProperEmail: "jose@work.com",
+			CommitName:  "josé",
+			CommitEmail: "jose@work.com",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		input         identities.Identity
+		expected      identities.Identity
+		expectedMatch []int
+	}{
+		{
+			name:          "name_only",
+			input:         ident("jane", "JANE@work.com"),
+			expected:      ident("Jane Doe", "JANE@work.com"),
+			expectedMatch: []int{0},
+		},
+		{
+			name:          "combined_entries",
+			input:         ident("jd", "jane@home.net"),
+			expected:      ident("Jane Doe", "jane@work.com"),
+			expectedMatch: []int{1, 2},
+		},
+		{
+			name:          "commit_name",
+			input:         ident("Bobby", "shared@work.com"),
+			expected:      ident("Bob", "bob@work.com"),
+			expectedMatch: []int{3},
+		},
+		{
+			name:          "commit_name_ascii_case",
+			input:         ident("BOBBY", "SHARED@work.com"),
+			expected:      ident("Bob", "bob@work.com"),
+			expectedMatch: []int{3},
+		},
+		{
+			name:          "commit_name_non_ascii_case",
+			input:         ident("JOSÉ", "jose@work.com"),
+			expected:      ident("JOSÉ", "jose@work.com"),
+			expectedMatch: []int{},
+		},
+		{
+			name:          "commit_name_mismatch",
+			input:         ident("sue", "shared@work.com"),
+			expected:      ident("sue", "shared@work.com"),
+			expectedMatch: []int{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, email, matches := m.Map(test.input.Name, test.input.Email)
+			result := ident(name, email)
+			if diff := cmp.Diff(test.expected, result); diff != "" {
+				t.Errorf("mapped identity is wrong:\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectedMatch, matches); diff != "" {
+				t.Errorf("matched entries are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMapAuthors(t *testing.T) {
+	m := mailmap.Mailmap{
+		{ProperName: "Jane Doe", ProperEmail: "jane@work.com", CommitEmail: "jane@home.net"},
+	}
+
+	authors := []git.Author{
+		{Name: "jane", Email: "jane@home.net"},
+		{Name: "Bob", Email: "bob@work.com"},
+		{Name: "Jane Doe", Email: "JANE@work.com"},
+		{Name: "Sue"},
+	}
+
+	expected := []git.Author{
+		{Name: "Jane Doe", Email: "jane@work.com"},
+		{Name: "Bob", Email: "bob@work.com"},
+		{Name: "Sue"},
+	}
+
+	result := m.MapAuthors(authors)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("mapped authors are wrong:\n%s", diff)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m := mailmap.Mailmap{
+		{ProperEmail: "jane@work.com", CommitEmail: "jane@home.net"},
+		{ProperName: "Gone", CommitEmail: "gone@example.com"},
+		{ProperName: "Sue", CommitEmail: "sue+git@work.com"}, // Name only
+	}
+	counts := identities.Counts{
+		ident("Jane Doe", "jane@work.com"): 3,
+		ident("Jane Doe", "jane@home.net"): 2,
+		ident("Bob", "bob@work.com"):       1,
+		ident("bob", "bob@home.net"):       1,
+		ident("Sue", "sue@work.com"):       2,
+		ident("S", "sue+git@work.com"):     1,
+		ident("Ann", "ann@work.com"):       1,
+		ident("Ann", "ann@home.net"):       1,
+	}
+
+	report := mailmap.Check(m, nil, counts)
+
+	if diff := cmp.Diff([]int{2, 0, 1}, report.Commits); diff != "" {
+		t.Errorf("commit counts are wrong:\n%s", diff)
+	}
+
+	if diff := cmp.Diff(m[1:2], mailmap.Mailmap(report.Unused)); diff != "" {
+		t.Errorf("unused entries are wrong:\n%s", diff)
+	}
+
+	expectedUnmerged := [][]identities.Identity{
+		{ident("bob", "bob@home.net"), ident("Bob", "bob@work.com")},
+	}
+	if diff := cmp.Diff(expectedUnmerged, report.Unmerged); diff != "" {
+		t.Errorf("unmerged identities are wrong:\n%s", diff)
+	}
+
+	if report.NumProblems() != 2 {
+		t.Errorf("expected 2 problems, got %d", report.NumProblems())
+	}
+}
@@ -7,6 +7,7 @@ const Green string = "\x1b[32m"
 const Red string = "\x1b[31m"
 const DefaultColor string = "\x1b[39m"
 
+const Bold string = "\x1b[1m"
 const Dim string = "\x1b[2m"
 const Invert string = "\x1b[7m"
 
@@ -0,0 +1,296 @@
+// Parses and evaluates arithmetic expressions used to score authors.
+//
+// An expression combines numbers and named variables with +, -, *, /, and
+// parentheses, e.g. "commits*3 + files + lines/100". Variables are checked when
+// the expression is parsed, so evaluating a parsed expression cannot fail.
+package score
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const maxLength = 1024
+const maxDepth = 64
+
+type node interface {
+	eval(values []float64) float64
+}
+
+type number float64
+
+func (n number) eval(values []float64) float64 {
+	return float64(n)
+}
+
+type variable int // Index into values
+
+func (v variable) eval(values []float64) float64 {
+	return values[v]
+}
+
+type negation struct {
+	operand node
+}
+
+func (n negation) eval(values []float64) float64 {
+	return -n.operand.eval(values)
+}
+
+type binary struct {
+	op    rune
+	left  node
+	right node
+}
+
+func (b binary) eval(values []float64) float64 {
+	left := b.left.eval(values)
+	right := b.right.eval(values)
+
+	switch b.op {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	case '/':
+		if right == 0 {
+			return 0 // Nobody wants a score of infinity
+		}
+		return left / right
+	default:
+		panic("unrecognized operator in switch")
+	}
+}
+
+// A parsed expression.
+type Expr struct {
+	source string
+	root   node
+}
+
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Evaluates the expression. Values are given in the same order as the
+// variables passed to Parse().
+func (e *Expr) Eval(values []float64) float64 {
+	return e.root.eval(values)
+}
+
+// Whether the string could be the name of a variable.
+func IsName(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for i, r := range s {
+		if !isNameRune(r, i == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNameRune(r rune, first bool) bool {
+	if r == '_' || unicode.IsLetter(r) {
+		return true
+	}
+
+	return !first && (r == '-' || unicode.IsDigit(r))
+}
+
+// Parses the expression, allowing only the given variables.
+func Parse(s string, vars []string) (_ *Expr, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("invalid score expression %q: %w", s, err)
+		}
+	}()
+
+	if len(s) > maxLength {
+		return nil, fmt.Errorf("longer than %d characters", maxLength)
+	}
+
+	p := parser{input: []rune(s), vars: vars}
+
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.atEnd() {
+		return nil, p.unexpected()
+	}
+
+	return &Expr{source: s, root: root}, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+	vars  []string
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.atEnd() && unicode.IsSpace(p.peek()) {
+		p.pos += 1
+	}
+}
+
+func (p *parser) unexpected() error {
+	if p.atEnd() {
+		return fmt.Errorf("unexpected end of expression")
+	}
+
+	return fmt.Errorf("unexpected %q at position %d", p.peek(), p.pos+1)
+}
+
+// expr := term (("+" | "-") term)*
+func (p *parser) parseExpr(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("nested more than %d levels deep", maxDepth)
+	}
+
+	left, err := p.parseTerm(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if p.atEnd() || (p.peek() != '+' && p.peek() != '-') {
+			return left, nil
+		}
+
+		op := p.peek()
+		p.pos += 1
+
+		right, err := p.parseTerm(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+// term := unary (("*" | "/") unary)*
+func (p *parser) parseTerm(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if p.atEnd() || (p.peek() != '*' && p.peek() != '/') {
+			return left, nil
+		}
+
+		op := p.peek()
+		p.pos += 1
+
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+// unary := "-" unary | primary
+func (p *parser) parseUnary(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("nested more than %d levels deep", maxDepth)
+	}
+
+	p.skipSpace()
+	if !p.atEnd() && p.peek() == '-' {
+		p.pos += 1
+
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return negation{operand}, nil
+	}
+
+	return p.parsePrimary(depth)
+}
+
+// primary := number | name | "(" expr ")"
+func (p *parser) parsePrimary(depth int) (node, error) {
+	p.skipSpace()
+	if p.atEnd() {
+		return nil, p.unexpected()
+	}
+
+	r := p.peek()
+	switch {
+	case r == '(':
+		p.pos += 1
+
+		inner, err := p.parseExpr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if p.atEnd() || p.peek() != ')' {
+			return nil, p.unexpected()
+		}
+		p.pos += 1
+
+		return inner, nil
+	case unicode.IsDigit(r) || r == '.':
+		start := p.pos
+		for !p.atEnd() && (unicode.IsDigit(p.peek()) || p.peek() == '.') {
+			p.pos += 1
+		}
+
+		text := string(p.input[start:p.pos])
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+
+		return number(n), nil
+	case isNameRune(r, true):
+		start := p.pos
+		for !p.atEnd() && isNameRune(p.peek(), false) && p.peek() != '-' {
+			p.pos += 1
+		}
+
+		name := string(p.input[start:p.pos])
+		for i, v := range p.vars {
+			if v == name {
+				return variable(i), nil
+			}
+		}
+
+		return nil, fmt.Errorf(
+			"unknown variable %q, expected one of: %s",
+			name,
+			strings.Join(p.vars, ", "),
+		)
+	default:
+		return nil, p.unexpected()
+	}
+}
@@ -0,0 +1,106 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/sinclairtarget/git-who/internal/score"
+)
+
+var vars = []string{"commits", "lines", "files"}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected float64
+	}{
+		{"commits", 4},
+		{"commits*3 + files + lines/100", 12 + 7 + 2.5},
+		{"commits + files * 2", 18},
+		{"(commits + files) * 2", 22},
+		{"lines - commits - files", 239},
+		{"lines-commits-files", 239},
+		{"lines / commits / 2", 31.25},
+		{"-commits + 1", -3},
+		{"--commits", 4},
+		{"0.5 * files", 3.5},
+		{"lines / (files - 7)", 0},
+		{"  commits*2  ", 8},
+	}
+
+	values := []float64{4, 250, 7}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := score.Parse(test.expr, vars)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result := expr.Eval(values)
+			if result != test.expected {
+				t.Errorf("expected %v, but got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"commits +",
+		"commits * * files",
+		"(commits + files",
+		"commits + files)",
+		"commits files",
+		"authors * 2",
+		"1.2.3",
+		"commits; rm -rf /",
+		"os.Exit(1)",
+	}
+
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			_, err := score.Parse(test, vars)
+			if err == nil {
+				t.Errorf("expected error parsing %q", test)
+			}
+		})
+	}
+}
+
+func TestParseTooDeep(t *testing.T) {
+	s := ""
+	for range 100 {
+		s += "("
+	}
+	s += "commits"
+	for range 100 {
+		s += ")"
+	}
+
+	_, err := score.Parse(s, vars)
+	if err == nil {
+		t.Errorf("expected error parsing deeply nested expression")
+	}
+}
+
+func TestIsName(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected bool
+	}{
+		{"commits", true},
+		{"team-default", true},
+		{"weights_2", true},
+		{"2weights", false},
+		{"commits*2", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if score.IsName(test.s) != test.expected {
+				t.Errorf("expected IsName(%q) to be %v", test.s, test.expected)
+			}
+		})
+	}
+}
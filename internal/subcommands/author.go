@@ -0,0 +1,256 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// The "author" subcommand prints everything we know about a single author's
+// contributions.
+//
+// The author is given by name or, if the string contains an "@", by email.
+func Author(
+	author string,
+	revs []string,
+	pathspecs []string,
+	useJson bool,
+	countMerges bool,
+	limit int,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"author\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called author()",
+		"author",
+		author,
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"useJson",
+		useJson,
+		"countMerges",
+		countMerges,
+		"limit",
+		limit,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		Mode:        tally.LinesMode,
+		CountMerges: countMerges,
+		FirstParent: firstParent,
+	}
+	if strings.Contains(author, "@") {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
+	} else {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
+	}
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	profile, err := func() (_ tally.Profile, err error) {
+		commits, finish := git.CommitsWithOpts(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			true,
+			diffOpts,
+			configFiles,
+		)
+		defer func() { err = errors.Join(err, finish()) }()
+
+		return tally.TallyProfile(commits, tallyOpts, author)
+	}()
+	if err != nil {
+		return fmt.Errorf("failed to tally commits for %q: %w", author, err)
+	}
+
+	if useJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(profile)
+		if err != nil {
+			return fmt.Errorf("error writing JSON to stdout: %w", err)
+		}
+
+		return nil
+	}
+
+	writeProfile(profile, limit)
+	return nil
+}
+
+func writeProfile(p tally.Profile, limit int) {
+	fmt.Printf(
+		"%s%s %s%s\n",
+		pretty.Bold,
+		p.Name,
+		format.GitEmail(p.Email),
+		pretty.Reset,
+	)
+	fmt.Println()
+
+	fmt.Printf(
+		"%-13s %s (%s)\n",
+		"First commit",
+		p.FirstCommitTime.Format(time.DateOnly),
+		format.RelativeTime(progStart, p.FirstCommitTime),
+	)
+	fmt.Printf(
+		"%-13s %s (%s)\n",
+		"Last commit",
+		p.LastCommitTime.Format(time.DateOnly),
+		format.RelativeTime(progStart, p.LastCommitTime),
+	)
+	fmt.Printf("%-13s %s\n", "Commits", format.Number(p.Commits))
+	fmt.Printf("%-13s %s\n", "Lines", formatLines(p.LinesAdded, p.LinesRemoved))
+	fmt.Printf("%-13s %s\n", "Files", format.Number(p.FileCount))
+	fmt.Printf("%-13s %s\n", "Active days", format.Number(p.ActiveDays))
+
+	months := p.CommitsByMonth
+	counts := []int{}
+	for _, m := range months {
+		counts = append(counts, m.Commits)
+	}
+	fmt.Println()
+	fmt.Printf(
+		"Commits by month, %s to %s\n",
+		months[0].Month,
+		months[len(months)-1].Month,
+	)
+	fmt.Printf("%s%s%s\n", pretty.Green, format.Sparkline(counts), pretty.Reset)
+
+	writePathStats("Top directories by commits", p.Dirs, tally.CommitMode, limit)
+	writePathStats("Top directories by lines", p.Dirs, tally.LinesMode, limit)
+	writePathStats("Top directories by files", p.Dirs, tally.FilesMode, limit)
+	writePathStats("Top files by commits", p.Files, tally.CommitMode, limit)
+	writePathStats("Top files by lines", p.Files, tally.LinesMode, limit)
+	writePathStats("Extensions by lines", p.Extensions, tally.LinesMode, limit)
+
+	fmt.Println()
+	fmt.Printf(
+		"Commit sizes in lines changed (median %s)\n",
+		format.Number(p.MedianSize),
+	)
+	for _, b := range p.CommitSizes {
+		fmt.Printf("  %-9s %7s\n", b.Label(), format.Number(b.Commits))
+	}
+
+	coContributors := p.CoContributors
+	if limit > 0 && limit < len(coContributors) {
+		coContributors = coContributors[:limit]
+	}
+	if len(coContributors) > 0 {
+		fmt.Println()
+		fmt.Println("Co-contributors by shared files")
+		for _, c := range coContributors {
+			fmt.Printf(
+				"  %7s  %s %s\n",
+				format.Number(c.SharedFiles),
+				c.Name,
+				format.GitEmail(c.Email),
+			)
+		}
+	}
+}
+
+func writePathStats(
+	title string,
+	stats []tally.PathStat,
+	mode tally.TallyMode,
+	limit int,
+) {
+	if len(stats) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(title)
+	for _, s := range tally.TopPaths(stats, mode, limit) {
+		var value string
+		switch mode {
+		case tally.CommitMode:
+			value = format.Number(s.Commits)
+		case tally.LinesMode:
+			value = format.Number(s.LinesAdded + s.LinesRemoved)
+		case tally.FilesMode:
+			value = format.Number(s.Files)
+		default:
+			panic("unrecognized tally mode in switch")
+		}
+
+		fmt.Printf("  %7s  %s\n", value, s.Path)
+	}
+}
+
+func formatLines(added int, removed int) string {
+	return fmt.Sprintf(
+		"%s+%s%s / %s-%s%s",
+		pretty.Green,
+		format.Number(added),
+		pretty.DefaultColor,
+		pretty.Red,
+		format.Number(removed),
+		pretty.DefaultColor,
+	)
+}
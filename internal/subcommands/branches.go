@@ -0,0 +1,52 @@
+package subcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+// Returns the author of the branch merged by each merge commit in the
+// first-parent history of the repository in the working directory, or of the
+// given repositories if there are any, for use as
+// tally.TallyOpts.BranchAuthors.
+func branchAuthors(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+) (map[string]git.Author, error) {
+	ctx = repos.context(ctx)
+
+	targets, err := findTargets(
+		ctx,
+		revs,
+		pathspecs,
+		repos,
+		ignoreRevsFiles,
+		recurseSubmodules,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			_ bool,
+		) (byCommit[git.Author], error) {
+			return git.MergedBranchAuthors(ctx, target.revs, target.configFiles)
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit branch authors: %w", err)
+	}
+
+	return authors, nil
+}
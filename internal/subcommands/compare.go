@@ -0,0 +1,316 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// One side of a comparison: the commits reachable from the given revisions,
+// optionally limited to a period of time.
+type CompareSide struct {
+	Name  string // What the user called the revisions, if anything
+	Revs  []string
+	Since string
+	Until string
+}
+
+func (s CompareSide) String() string {
+	name := s.Name
+	if len(name) == 0 {
+		name = strings.Join(s.Revs, " ")
+	}
+
+	parts := []string{name}
+	if len(s.Since) > 0 {
+		parts = append(parts, "since "+s.Since)
+	}
+	if len(s.Until) > 0 {
+		parts = append(parts, "until "+s.Until)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// The "compare" subcommand tallies two revision ranges or time periods and
+// prints how each author's contributions changed from the first to the second.
+func Compare(
+	sideA CompareSide,
+	sideB CompareSide,
+	pathspecs []string,
+	mode tally.TallyMode,
+	showEmail bool,
+	useCsv bool,
+	countMerges bool,
+	limit int,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"compare\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called compare()",
+		"sideA",
+		sideA,
+		"sideB",
+		sideB,
+		"pathspecs",
+		pathspecs,
+		"mode",
+		mode,
+		"showEmail",
+		showEmail,
+		"useCsv",
+		useCsv,
+		"countMerges",
+		countMerges,
+		"limit",
+		limit,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		Mode:        mode,
+		CountMerges: countMerges,
+		FirstParent: firstParent,
+	}
+	if showEmail {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
+	} else {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
+	}
+
+	tallySide := func(side CompareSide) (map[string]tally.Tally, error) {
+		filters := cmd.LogFilters{
+			Since:       side.Since,
+			Until:       side.Until,
+			Authors:     authors,
+			Nauthors:    nauthors,
+			FirstParent: firstParent,
+		}
+
+		return tallyRepo(
+			ctx,
+			side.Revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			false,
+			tallyOpts,
+		)
+	}
+
+	talliesA, err := tallySide(sideA)
+	if err != nil {
+		return err
+	}
+
+	talliesB, err := tallySide(sideB)
+	if err != nil {
+		return err
+	}
+
+	deltas := tally.Deltas(talliesA, talliesB, mode)
+
+	numFilteredOut := 0
+	if limit > 0 && limit < len(deltas) {
+		numFilteredOut = len(deltas) - limit
+		deltas = deltas[:limit]
+	}
+
+	if useCsv {
+		return writeCompareCsv(deltas, showEmail)
+	}
+
+	writeCompare(deltas, sideA, sideB, mode, showEmail, numFilteredOut)
+	return nil
+}
+
+// Name of the metric being compared, used in column headers.
+func compareMetric(mode tally.TallyMode) string {
+	switch mode {
+	case tally.CommitMode:
+		return "Commits"
+	case tally.LinesMode:
+		return "Lines"
+	case tally.LinesAddedMode:
+		return "Added"
+	case tally.LinesRemovedMode:
+		return "Removed"
+	case tally.FilesMode:
+		return "Files"
+	case tally.ActiveDaysMode:
+		return "Days"
+	default:
+		panic("unrecognized mode in switch")
+	}
+}
+
+// Relative change as a signed percentage, or whether the author is new or
+// gone if there is nothing to compare against.
+func formatRelativeChange(d tally.Delta) string {
+	if d.IsNew() {
+		return "new"
+	} else if d.IsGone() {
+		return "gone"
+	}
+
+	rel, ok := d.RelativeChange()
+	if !ok {
+		return "-"
+	}
+
+	if rel > 0 {
+		return "+" + format.Percent(rel)
+	}
+
+	return format.Percent(rel)
+}
+
+func writeCompareCsv(deltas []tally.Delta, showEmail bool) error {
+	w := csv.NewWriter(os.Stdout)
+
+	columnHeaders := []string{"name"}
+	if showEmail {
+		columnHeaders = append(columnHeaders, "email")
+	}
+	columnHeaders = append(
+		columnHeaders,
+		"a",
+		"b",
+		"change",
+		"relative change",
+		"status",
+	)
+	w.Write(columnHeaders)
+
+	for _, d := range deltas {
+		record := []string{d.AuthorName}
+		if showEmail {
+			record = append(record, d.AuthorEmail)
+		}
+
+		rel := ""
+		if r, ok := d.RelativeChange(); ok {
+			rel = strconv.FormatFloat(r, 'f', 2, 64)
+		}
+
+		status := ""
+		if d.IsNew() {
+			status = "new"
+		} else if d.IsGone() {
+			status = "gone"
+		}
+
+		record = append(
+			record,
+			strconv.FormatInt(d.Before, 10),
+			strconv.FormatInt(d.After, 10),
+			strconv.FormatInt(d.Change(), 10),
+			rel,
+			status,
+		)
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+func writeCompare(
+	deltas []tally.Delta,
+	sideA CompareSide,
+	sideB CompareSide,
+	mode tally.TallyMode,
+	showEmail bool,
+	numFilteredOut int,
+) {
+	if len(deltas) == 0 {
+		return
+	}
+
+	nameWidth := 32
+	if showEmail {
+		nameWidth = 48
+	}
+
+	fmt.Printf("%sA:%s %s\n", pretty.Bold, pretty.Reset, sideA)
+	fmt.Printf("%sB:%s %s\n", pretty.Bold, pretty.Reset, sideB)
+	fmt.Println()
+
+	metric := compareMetric(mode)
+	fmt.Printf(
+		"%-*s %10s %10s %10s %9s\n",
+		nameWidth,
+		"Author",
+		metric+" A",
+		metric+" B",
+		"Change",
+		"Rel.",
+	)
+
+	for _, d := range deltas {
+		name := d.AuthorName
+		if showEmail {
+			name = fmt.Sprintf("%s %s", name, format.GitEmail(d.AuthorEmail))
+		}
+		name = runewidth.FillRight(format.Abbrev(name, nameWidth), nameWidth)
+
+		style := ""
+		if d.IsGone() {
+			style = pretty.Dim
+		}
+
+		fmt.Printf(
+			"%s%s %10s %10s %s %9s%s\n",
+			style,
+			name,
+			format.Number(int(d.Before)),
+			format.Number(int(d.After)),
+			formatChange(int(d.Change()), 10),
+			formatRelativeChange(d),
+			pretty.Reset,
+		)
+	}
+
+	if numFilteredOut > 0 {
+		fmt.Printf("...%s more...\n", format.Number(numFilteredOut))
+	}
+}
@@ -22,6 +22,9 @@ func Dump(
 	until string,
 	authors []string,
 	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
 ) (err error) {
 	defer func() {
 		if err != nil {
@@ -45,6 +48,12 @@ func Dump(
 		authors,
 		"nauthors",
 		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
 	)
 
 	start := time.Now()
@@ -53,10 +62,11 @@ func Dump(
 	defer cancel()
 
 	filters := cmd.LogFilters{
-		Since:    since,
-		Until:    until,
-		Authors:  authors,
-		Nauthors: nauthors,
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
 	}
 
 	gitRootPath, err := git.GetRoot()
@@ -64,7 +74,10 @@ func Dump(
 		return err
 	}
 
-	configFiles, err := config.DetectSupplementalFiles(gitRootPath)
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
 	if err != nil {
 		return err
 	}
@@ -75,6 +88,7 @@ func Dump(
 		pathspecs,
 		filters,
 		!short,
+		diffOpts,
 		configFiles.HasMailmap(),
 	)
 	if err != nil {
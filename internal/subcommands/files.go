@@ -0,0 +1,303 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// The "files" subcommand lists the paths edited by a single author.
+//
+// The author is given by name or, if the string contains an "@", by email.
+func Files(
+	revs []string,
+	pathspecs []string,
+	author string,
+	mode tally.TallyMode,
+	useCsv bool,
+	useJson bool,
+	onlyWorkingTree bool,
+	onlyTop bool,
+	followRenames bool,
+	limit int,
+	since string,
+	until string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"files\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called files()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"author",
+		author,
+		"mode",
+		mode,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"onlyWorkingTree",
+		onlyWorkingTree,
+		"onlyTop",
+		onlyTop,
+		"followRenames",
+		followRenames,
+		"limit",
+		limit,
+		"since",
+		since,
+		"until",
+		until,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		Mode:          mode,
+		FollowRenames: followRenames,
+		FirstParent:   firstParent,
+	}
+	if strings.Contains(author, "@") {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
+	} else {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
+	}
+
+	// No author filter, since we need everyone's commits to find out whether
+	// the author is the top contributor
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	target, err := workingDirTarget(revs, pathspecs, gitRootPath, configFiles)
+	if err != nil {
+		return err
+	}
+
+	byPath, err := tallyTargetsByPath(
+		ctx,
+		[]tallyTarget{target},
+		filters,
+		diffOpts,
+		tallyOpts,
+	)
+	if err != nil {
+		return err
+	}
+
+	var wtreeset map[string]bool
+	if onlyWorkingTree {
+		wtreeset, err = git.WorkingTreeFiles(pathspecs, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	pathTallies := []tally.PathTally{}
+	for _, t := range byPath.AuthorPaths(author, mode) {
+		if onlyWorkingTree && !wtreeset[t.Path] {
+			continue
+		}
+		if onlyTop && !t.IsTop {
+			continue
+		}
+
+		pathTallies = append(pathTallies, t)
+	}
+
+	if limit > 0 && limit < len(pathTallies) {
+		pathTallies = pathTallies[:limit]
+	}
+
+	if useCsv {
+		return writeFilesCsv(pathTallies)
+	} else if useJson {
+		return writeFilesJson(pathTallies)
+	}
+
+	writeFilesTable(pathTallies, mode)
+	return nil
+}
+
+func writeFilesCsv(pathTallies []tally.PathTally) error {
+	w := csv.NewWriter(os.Stdout)
+
+	w.Write([]string{
+		"path",
+		"commits",
+		"lines added",
+		"lines removed",
+		"last commit time",
+		"first commit time",
+		"top contributor",
+	})
+
+	for _, t := range pathTallies {
+		record := []string{
+			t.Path,
+			strconv.Itoa(t.Commits),
+			strconv.Itoa(t.LinesAdded),
+			strconv.Itoa(t.LinesRemoved),
+			t.LastCommitTime.Format(time.RFC3339),
+			t.FirstCommitTime.Format(time.RFC3339),
+			strconv.FormatBool(t.IsTop),
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type fileRecord struct {
+	Path            string    `json:"path"`
+	Commits         int       `json:"commits"`
+	LinesAdded      int       `json:"lines_added"`
+	LinesRemoved    int       `json:"lines_removed"`
+	LastCommitTime  time.Time `json:"last_commit_time"`
+	FirstCommitTime time.Time `json:"first_commit_time"`
+	IsTop           bool      `json:"top_contributor"`
+}
+
+func writeFilesJson(pathTallies []tally.PathTally) error {
+	records := []fileRecord{}
+	for _, t := range pathTallies {
+		records = append(records, fileRecord{
+			Path:            t.Path,
+			Commits:         t.Commits,
+			LinesAdded:      t.LinesAdded,
+			LinesRemoved:    t.LinesRemoved,
+			LastCommitTime:  t.LastCommitTime,
+			FirstCommitTime: t.FirstCommitTime,
+			IsTop:           t.IsTop,
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writeFilesTable(pathTallies []tally.PathTally, mode tally.TallyMode) {
+	if len(pathTallies) == 0 {
+		return
+	}
+
+	colwidth := wideWidth
+	pathWidth := colwidth - 2 - 12 - 8 - 19
+
+	var build strings.Builder
+	for _ = range colwidth - 2 {
+		build.WriteRune('─')
+	}
+	rule := build.String()
+
+	timeHeader := "Last Edit"
+	if mode == tally.FirstModifiedMode {
+		timeHeader = "First Edit"
+	}
+
+	fmt.Printf("┌%s┐\n", rule)
+	fmt.Printf(
+		"│%-*s %-11s %7s  %17s│\n",
+		pathWidth,
+		"Path",
+		timeHeader,
+		"Commits",
+		"Lines (+/-)",
+	)
+	fmt.Printf("├%s┤\n", rule)
+
+	totalRows := len(pathTallies)
+	for i, t := range pathTallies {
+		alternating := ""
+		if totalRows > maxBeforeColorAlternating && i%2 == 1 {
+			alternating = pretty.Invert
+		}
+
+		editTime := t.LastCommitTime
+		if mode == tally.FirstModifiedMode {
+			editTime = t.FirstCommitTime
+		}
+
+		p := format.Abbrev(t.Path, pathWidth)
+		p = runewidth.FillRight(p, pathWidth)
+
+		fmt.Printf(
+			"│%s%s %-11s %7s  %s%7s%s / %s%7s%s%s│\n",
+			alternating,
+			p,
+			format.RelativeTime(progStart, editTime),
+			format.Number(t.Commits),
+			pretty.Green,
+			format.Number(t.LinesAdded),
+			pretty.DefaultColor,
+			pretty.Red,
+			format.Number(t.LinesRemoved),
+			pretty.DefaultColor,
+			pretty.Reset,
+		)
+	}
+
+	fmt.Printf("└%s┘\n", rule)
+}
@@ -6,6 +6,7 @@ import (
 	"math"
 	"os"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,13 +25,23 @@ const barWidth = 36
 func Hist(
 	revs []string,
 	pathspecs []string,
+	repos RepoSet,
 	mode tally.TallyMode,
 	showEmail bool,
 	countMerges bool,
+	identity tally.IdentityMode,
+	autoMerge bool,
+	splitSquashes bool,
+	dateMode tally.DateMode,
 	since string,
 	until string,
 	authors []string,
 	nauthors []string,
+	omitCherryPicks bool,
+	firstParent bool,
+	creditBranchAuthor bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
 ) (err error) {
 	defer func() {
 		if err != nil {
@@ -44,12 +55,22 @@ func Hist(
 		revs,
 		"pathspecs",
 		pathspecs,
+		"repos",
+		repos,
 		"mode",
 		mode,
 		"showEmail",
 		showEmail,
 		"countMerges",
 		countMerges,
+		"identity",
+		identity,
+		"autoMerge",
+		autoMerge,
+		"splitSquashes",
+		splitSquashes,
+		"dateMode",
+		dateMode,
 		"since",
 		since,
 		"until",
@@ -58,24 +79,86 @@ func Hist(
 		authors,
 		"nauthors",
 		nauthors,
+		"omitCherryPicks",
+		omitCherryPicks,
+		"firstParent",
+		firstParent,
+		"creditBranchAuthor",
+		creditBranchAuthor,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tallyOpts := tally.TallyOpts{Mode: mode, CountMerges: countMerges}
+	tallyOpts := tally.TallyOpts{
+		Mode:        mode,
+		CountMerges: countMerges,
+		Identity:    identity,
+		Date:        dateMode,
+		FirstParent: firstParent,
+	}
 	if showEmail {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
 	} else {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
 	}
 
-	populateDiffs := tallyOpts.IsDiffMode()
 	filters := cmd.LogFilters{
-		Since:    since,
-		Until:    until,
-		Authors:  authors,
-		Nauthors: nauthors,
+		Since:           since,
+		Until:           until,
+		Authors:         authors,
+		Nauthors:        nauthors,
+		OmitCherryPicks: omitCherryPicks,
+		FirstParent:     firstParent,
+	}
+
+	if creditBranchAuthor {
+		tallyOpts.BranchAuthors, err = branchAuthors(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			ignoreRevsFiles,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if splitSquashes {
+		tallyOpts.SquashCoAuthors, err = squashCoAuthors(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			filters,
+			ignoreRevsFiles,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if autoMerge {
+		tallyOpts.Merge, err = mergeIdentities(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			filters,
+			ignoreRevsFiles,
+			false,
+			identity,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	var end time.Time // Default is zero time, meaning use last commit
@@ -84,52 +167,42 @@ func Hist(
 		end = time.Now()
 	}
 
-	gitRootPath, err := git.GetRoot()
-	if err != nil {
-		return err
-	}
+	var buckets []tally.TimeBucket
+	if !repos.IsEmpty() {
+		ctx := repos.context(ctx)
 
-	configFiles, err := config.DetectSupplementalFiles(gitRootPath)
-	if err != nil {
-		return err
-	}
+		targets, err := findRepoTargets(ctx, repos, ignoreRevsFiles, false)
+		if err != nil {
+			return err
+		}
 
-	var buckets []tally.TimeBucket
-	if populateDiffs && runtime.GOMAXPROCS(0) > 1 {
-		buckets, err = concurrent.TallyCommitsTimeline(
+		series, err := tallyTargetsByDate(
 			ctx,
-			revs,
-			pathspecs,
+			targets,
 			filters,
-			configFiles,
+			diffOpts,
 			tallyOpts,
-			end,
-			cache.GetCache(gitRootPath, configFiles),
-			pretty.AllowDynamic(os.Stdout),
 		)
 		if err != nil {
 			return err
 		}
-	} else {
-		buckets, err = func() (_ []tally.TimeBucket, err error) {
-			commits, finish := git.CommitsWithOpts(
-				ctx,
-				revs,
-				pathspecs,
-				filters,
-				populateDiffs,
-				configFiles,
-			)
-			defer func() { err = finish() }()
 
-			buckets, err := tally.TallyCommitsTimeline(
-				commits,
-				tallyOpts,
-				end,
-			)
-			return buckets, err
-		}()
+		if len(until) == 0 && !slices.ContainsFunc(targets, hasRevs) {
+			end = time.Now()
+		}
 
+		buckets = series.Timeline(end)
+	} else {
+		buckets, err = tallyRepoTimeline(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			tallyOpts,
+			end,
+		)
 		if err != nil {
 			return err
 		}
@@ -143,15 +216,69 @@ func Hist(
 	// -- Draw bar plot --
 	maxVal := barWidth
 	for _, bucket := range buckets {
-		if bucket.TotalValue(mode) > maxVal {
-			maxVal = bucket.TotalValue(mode)
-		}
+		// The winner's net lines can exceed the total for the bucket
+		maxVal = max(maxVal, bucket.TotalValue(mode), bucket.Value(mode))
 	}
 
 	drawPlot(buckets, maxVal, mode, showEmail)
 	return nil
 }
 
+// Tallies commits in the repository in the working directory into time
+// buckets.
+func tallyRepoTimeline(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	tallyOpts tally.TallyOpts,
+	end time.Time,
+) (_ []tally.TimeBucket, err error) {
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	populateDiffs := tallyOpts.IsDiffMode()
+	if populateDiffs && runtime.GOMAXPROCS(0) > 1 {
+		return concurrent.TallyCommitsTimeline(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			configFiles,
+			tallyOpts,
+			end,
+			cache.GetCache(gitRootPath, configFiles, diffOpts),
+			pretty.AllowDynamic(os.Stdout),
+		)
+	}
+
+	commits, finish := git.CommitsWithOpts(
+		ctx,
+		revs,
+		pathspecs,
+		filters,
+		populateDiffs,
+		diffOpts,
+		configFiles,
+	)
+	defer func() { err = finish() }()
+
+	return tally.TallyCommitsTimeline(commits, tallyOpts, end)
+}
+
 func drawPlot(
 	buckets []tally.TimeBucket,
 	maxVal int,
@@ -170,10 +297,14 @@ func drawPlot(
 			(float64(total) / float64(maxVal)) * float64(barWidth),
 		))
 
+		// Net lines can be negative, which we draw as an empty bar
+		clampedValue = max(clampedValue, 0)
+		clampedTotal = max(clampedTotal, clampedValue)
+
 		valueBar := strings.Repeat("#", clampedValue)
 		totalBar := strings.Repeat("-", clampedTotal-clampedValue)
 
-		if value > 0 {
+		if value != 0 {
 			tallyPart := fmtHistTally(
 				bucket.Tally,
 				mode,
@@ -210,7 +341,9 @@ func fmtHistTally(
 		metric = fmt.Sprintf("(%s)", format.Number(t.Commits))
 	case tally.FilesMode:
 		metric = fmt.Sprintf("(%s)", format.Number(t.FileCount))
-	case tally.LinesMode:
+	case tally.NetLinesMode:
+		metric = fmt.Sprintf("(%s)", formatNetLines(t, 0))
+	case tally.LinesMode, tally.LinesAddedMode, tally.LinesRemovedMode:
 		metric = fmt.Sprintf(
 			"(%s%s%s / %s%s%s)",
 			pretty.Green,
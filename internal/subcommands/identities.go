@@ -0,0 +1,193 @@
+package subcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/identities"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// The "identities" subcommand prints clusters of author identities that likely
+// belong to the same person.
+func Identities(
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	proposeMailmap bool,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"identities\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called identities()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"repos",
+		repos,
+		"proposeMailmap",
+		proposeMailmap,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	counts, err := countIdentities(
+		ctx,
+		revs,
+		pathspecs,
+		repos,
+		filters,
+		ignoreRevsFiles,
+		false,
+		tally.AuthorIdentity,
+	)
+	if err != nil {
+		return err
+	}
+
+	clusters := []identities.Cluster{}
+	for _, cluster := range identities.Clusters(counts) {
+		if len(cluster) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	if proposeMailmap {
+		for _, entry := range identities.MailmapEntries(clusters) {
+			fmt.Println(entry)
+		}
+
+		return nil
+	}
+
+	for i, cluster := range clusters {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		fmt.Printf(
+			"%s (%s commits)\n",
+			cluster.Canonical(),
+			format.Number(cluster.Commits()),
+		)
+		for _, m := range cluster {
+			fmt.Printf("  %7s  %s\n", format.Number(m.Commits), m.Identity)
+		}
+	}
+
+	return nil
+}
+
+// Returns a function that merges each identity in the commit history into the
+// canonical identity of its cluster, for use as tally.TallyOpts.Merge.
+func mergeIdentities(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	filters cmd.LogFilters,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+	identity tally.IdentityMode,
+) (func(name, email string) (string, string), error) {
+	counts, err := countIdentities(
+		ctx,
+		revs,
+		pathspecs,
+		repos,
+		filters,
+		ignoreRevsFiles,
+		recurseSubmodules,
+		identity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cluster identities: %w", err)
+	}
+
+	return identities.Merger(identities.Clusters(counts)), nil
+}
+
+// Counts commits by each identity in the repository in the working directory,
+// or in the given repositories if there are any.
+func countIdentities(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	filters cmd.LogFilters,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+	identity tally.IdentityMode,
+) (identities.Counts, error) {
+	ctx = repos.context(ctx)
+
+	targets, err := findTargets(
+		ctx,
+		revs,
+		pathspecs,
+		repos,
+		ignoreRevsFiles,
+		recurseSubmodules,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			_ bool,
+		) (_ identities.Counts, err error) {
+			commits, finish := git.CommitsWithOpts(
+				ctx,
+				target.revs,
+				target.pathspecs,
+				filters,
+				false,
+				cmd.DiffOpts{},
+				target.configFiles,
+			)
+			defer func() { err = finish() }()
+
+			return identities.CountCommits(commits, identity), nil
+		},
+	)
+}
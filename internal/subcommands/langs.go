@@ -0,0 +1,330 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/langs"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// The "langs" subcommand breaks down contributions by programming language,
+// both overall and per author.
+func Langs(
+	revs []string,
+	pathspecs []string,
+	mode tally.TallyMode,
+	showEmail bool,
+	useCsv bool,
+	useJson bool,
+	countMerges bool,
+	limit int,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"langs\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called langs()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"mode",
+		mode,
+		"showEmail",
+		showEmail,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"countMerges",
+		countMerges,
+		"limit",
+		limit,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		Mode:        mode,
+		CountMerges: countMerges,
+		FirstParent: firstParent,
+	}
+	if showEmail {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
+	} else {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
+	}
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	target, err := workingDirTarget(revs, pathspecs, gitRootPath, configFiles)
+	if err != nil {
+		return err
+	}
+
+	byPath, err := tallyTargetsByPath(
+		ctx,
+		[]tallyTarget{target},
+		filters,
+		diffOpts,
+		tallyOpts,
+	)
+	if err != nil {
+		return err
+	}
+
+	groups := byPath.Groups(langs.Classify, mode)
+	if limit > 0 {
+		for i, g := range groups {
+			if limit < len(g.Authors) {
+				groups[i].Authors = g.Authors[:limit]
+			}
+		}
+	}
+
+	if useCsv {
+		return writeLangsCsv(groups, showEmail)
+	} else if useJson {
+		return writeLangsJson(groups)
+	}
+
+	writeLangs(groups, showEmail)
+	return nil
+}
+
+func langCsvRecord(
+	lang string,
+	t tally.FinalTally,
+	showEmail bool,
+	isTotal bool,
+) []string {
+	record := []string{lang}
+	if isTotal {
+		record = append(record, "")
+		if showEmail {
+			record = append(record, "")
+		}
+	} else {
+		record = append(record, t.AuthorName)
+		if showEmail {
+			record = append(record, t.AuthorEmail)
+		}
+	}
+
+	return append(
+		record,
+		strconv.Itoa(t.Commits),
+		strconv.Itoa(t.LinesAdded),
+		strconv.Itoa(t.LinesRemoved),
+		strconv.Itoa(t.FileCount),
+	)
+}
+
+// Writes a row for each language overall, with an empty name, followed by a
+// row for each of its authors.
+func writeLangsCsv(groups []tally.GroupTally, showEmail bool) error {
+	w := csv.NewWriter(os.Stdout)
+
+	columnHeaders := []string{"language", "name"}
+	if showEmail {
+		columnHeaders = append(columnHeaders, "email")
+	}
+	columnHeaders = append(
+		columnHeaders,
+		"commits",
+		"lines added",
+		"lines removed",
+		"files",
+	)
+	w.Write(columnHeaders)
+
+	for _, g := range groups {
+		records := [][]string{
+			langCsvRecord(g.Group, g.Total, showEmail, true),
+		}
+		for _, t := range g.Authors {
+			record := langCsvRecord(g.Group, t, showEmail, false)
+			records = append(records, record)
+		}
+
+		if err := w.WriteAll(records); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type langAuthorRecord struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Commits      int    `json:"commits"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	Files        int    `json:"files"`
+}
+
+type langRecord struct {
+	Language     string             `json:"language"`
+	Commits      int                `json:"commits"`
+	LinesAdded   int                `json:"lines_added"`
+	LinesRemoved int                `json:"lines_removed"`
+	Files        int                `json:"files"`
+	Authors      []langAuthorRecord `json:"authors"`
+}
+
+func writeLangsJson(groups []tally.GroupTally) error {
+	records := []langRecord{}
+	for _, g := range groups {
+		record := langRecord{
+			Language:     g.Group,
+			Commits:      g.Total.Commits,
+			LinesAdded:   g.Total.LinesAdded,
+			LinesRemoved: g.Total.LinesRemoved,
+			Files:        g.Total.FileCount,
+			Authors:      []langAuthorRecord{},
+		}
+		for _, t := range g.Authors {
+			record.Authors = append(record.Authors, langAuthorRecord{
+				Name:         t.AuthorName,
+				Email:        t.AuthorEmail,
+				Commits:      t.Commits,
+				LinesAdded:   t.LinesAdded,
+				LinesRemoved: t.LinesRemoved,
+				Files:        t.FileCount,
+			})
+		}
+
+		records = append(records, record)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writeLangs(groups []tally.GroupTally, showEmail bool) {
+	if len(groups) == 0 {
+		return
+	}
+
+	nameWidth := 32
+	if showEmail {
+		nameWidth = 48
+	}
+
+	fmt.Printf(
+		"%-*s %7s  %17s  %7s\n",
+		nameWidth,
+		"Language / Author",
+		"Commits",
+		"Lines (+/-)",
+		"Files",
+	)
+
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		writeLangRow(g.Group, g.Total, nameWidth, pretty.Bold)
+
+		for _, t := range g.Authors {
+			name := t.AuthorName
+			if showEmail {
+				name = fmt.Sprintf("%s %s", name, format.GitEmail(t.AuthorEmail))
+			}
+
+			writeLangRow("  "+name, t, nameWidth, "")
+		}
+	}
+}
+
+func writeLangRow(
+	name string,
+	t tally.FinalTally,
+	nameWidth int,
+	style string,
+) {
+	name = runewidth.FillRight(format.Abbrev(name, nameWidth), nameWidth)
+
+	fmt.Printf(
+		"%s%s %7s  %s%7s%s / %s%7s%s  %7s%s\n",
+		style,
+		name,
+		format.Number(t.Commits),
+		pretty.Green,
+		format.Number(t.LinesAdded),
+		pretty.DefaultColor,
+		pretty.Red,
+		format.Number(t.LinesRemoved),
+		pretty.DefaultColor,
+		format.Number(t.FileCount),
+		pretty.Reset,
+	)
+}
@@ -0,0 +1,152 @@
+package subcommands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/identities"
+	"github.com/sinclairtarget/git-who/internal/mailmap"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// The "mailmap" subcommand reports how the repo and global mailmap files apply
+// to the commit history, along with any problems found in them.
+//
+// In check mode, only problems are printed and an error is returned if there
+// are any.
+func Mailmap(revs []string, check bool) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"mailmap\": %w", err)
+		}
+	}()
+
+	logger().Debug("called mailmap()", "revs", revs, "check", check)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(gitRootPath, nil)
+	if err != nil {
+		return err
+	}
+
+	paths := []string{}
+	if len(configFiles.RepoMailmapPath) > 0 {
+		paths = append(paths, configFiles.RepoMailmapPath)
+	}
+	if len(configFiles.GlobalMailmapPath) > 0 {
+		paths = append(paths, configFiles.GlobalMailmapPath)
+	}
+
+	if len(paths) == 0 {
+		// Nothing to report or check, in either mode
+		fmt.Println("No mailmap found")
+		return nil
+	}
+
+	m, parseErrs, err := mailmap.ReadFiles(paths...)
+	if err != nil {
+		return err
+	}
+
+	counts, err := func() (_ identities.Counts, err error) {
+		// Empty config files so we see identities as they were committed
+		commits, finish := git.CommitsWithOpts(
+			ctx,
+			revs,
+			nil,
+			cmd.LogFilters{},
+			false,
+			cmd.DiffOpts{},
+			config.SupplementalFiles{},
+		)
+		defer func() { err = finish() }()
+
+		return identities.CountCommits(
+			commits,
+			tally.AuthorOrCommitterIdentity,
+		), nil
+	}()
+	if err != nil {
+		return err
+	}
+
+	report := mailmap.Check(m, parseErrs, counts)
+
+	if !check {
+		for i, entry := range m {
+			fmt.Printf(
+				"%s:%d: %s (%s commits)\n",
+				displayPath(entry.File),
+				entry.Line,
+				entry,
+				format.Number(report.Commits[i]),
+			)
+		}
+
+		if report.NumProblems() > 0 {
+			fmt.Println()
+		}
+	}
+
+	for _, parseErr := range report.ParseErrors {
+		fmt.Printf(
+			"%s:%d: malformed entry: %s\n",
+			displayPath(parseErr.File),
+			parseErr.Line,
+			parseErr.Msg,
+		)
+	}
+
+	for _, entry := range report.Unused {
+		fmt.Printf(
+			"%s:%d: entry matches no commits: %s\n",
+			displayPath(entry.File),
+			entry.Line,
+			entry,
+		)
+	}
+
+	for _, group := range report.Unmerged {
+		idents := []string{}
+		for _, ident := range group {
+			idents = append(idents, ident.String())
+		}
+
+		fmt.Printf("identities not merged: %s\n", strings.Join(idents, ", "))
+	}
+
+	if check && report.NumProblems() > 0 {
+		return fmt.Errorf("found %d problem(s)", report.NumProblems())
+	}
+
+	return nil
+}
+
+// Returns the path relative to the working directory if it is under it.
+func displayPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+
+	rel, err := filepath.Rel(wd, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+
+	return rel
+}
@@ -0,0 +1,425 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+	"github.com/sinclairtarget/git-who/internal/utils/timeutils"
+)
+
+// The "orphans" subcommand lists the working-tree paths where departed
+// authors own most of the code, along with a suggested new owner.
+//
+// Authors count as departed if they match one of the given names or emails, or
+// if they have not committed anywhere in the repo since inactiveSince ago.
+//
+// Like tree, authors are told apart by name unless showEmail is set, so an
+// author who committed under several emails is departed under all of them.
+func Orphans(
+	revs []string,
+	pathspecs []string,
+	departedAuthors []string,
+	inactiveSince string,
+	mode tally.TallyMode,
+	useCsv bool,
+	useJson bool,
+	showEmail bool,
+	followRenames bool,
+	limit int,
+	since string,
+	until string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"orphans\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called orphans()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"departedAuthors",
+		departedAuthors,
+		"inactiveSince",
+		inactiveSince,
+		"mode",
+		mode,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"showEmail",
+		showEmail,
+		"followRenames",
+		followRenames,
+		"limit",
+		limit,
+		"since",
+		since,
+		"until",
+		until,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	key := func(c git.Commit) string { return c.AuthorName }
+	if showEmail {
+		key = func(c git.Commit) string { return c.AuthorEmail }
+	}
+
+	var lastActive map[string]time.Time
+	var cutoff time.Time
+	if len(inactiveSince) > 0 {
+		cutoff, err = timeutils.Ago(progStart, inactiveSince)
+		if err != nil {
+			return err
+		}
+
+		lastActive, err = tallyLastActive(
+			ctx,
+			revs,
+			diffOpts,
+			ignoreRevsFiles,
+			key,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	departedKeys := map[string]bool{}
+	if len(departedAuthors) > 0 {
+		departedKeys, err = findAuthorKeys(
+			ctx,
+			revs,
+			diffOpts,
+			ignoreRevsFiles,
+			departedAuthors,
+			key,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	departed := func(t tally.FinalTally) bool {
+		k := key(git.Commit{AuthorName: t.AuthorName, AuthorEmail: t.AuthorEmail})
+		if departedKeys[k] {
+			return true
+		}
+
+		last, ok := lastActive[k]
+		return ok && last.Before(cutoff)
+	}
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	tallyOpts := tally.TallyOpts{
+		Mode:          mode,
+		Key:           key,
+		FollowRenames: followRenames,
+		FirstParent:   firstParent,
+	}
+
+	root, err := tallyRepoTree(
+		ctx,
+		revs,
+		pathspecs,
+		filters,
+		diffOpts,
+		ignoreRevsFiles,
+		false,
+		tallyOpts,
+	)
+	if errors.Is(err, tally.EmptyTreeErr) {
+		logger().Debug("Tree was empty.")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	orphans := root.Rank(mode).Orphans(mode, departed)
+	if limit > 0 && limit < len(orphans) {
+		orphans = orphans[:limit]
+	}
+
+	if useCsv {
+		return writeOrphansCsv(orphans)
+	} else if useJson {
+		return writeOrphansJson(orphans)
+	}
+
+	writeOrphansTable(orphans, showEmail)
+	return nil
+}
+
+// Returns when each author last committed anywhere in the repo. Authors are
+// told apart by the given key, which should be the one we tally by.
+func tallyLastActive(
+	ctx context.Context,
+	revs []string,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	key func(c git.Commit) string,
+) (map[string]time.Time, error) {
+	tallies, err := tallyRepo(
+		ctx,
+		revs,
+		nil,
+		cmd.LogFilters{},
+		diffOpts,
+		ignoreRevsFiles,
+		false,
+		tally.TallyOpts{
+			Mode:        tally.CommitMode,
+			Key:         key,
+			CountMerges: true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lastActive := map[string]time.Time{}
+	for k, t := range tallies {
+		lastActive[k] = t.Final().LastCommitTime
+	}
+
+	return lastActive, nil
+}
+
+// Returns the keys of the authors who committed anywhere in the repo under one
+// of the given names or emails.
+func findAuthorKeys(
+	ctx context.Context,
+	revs []string,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	authors []string,
+	key func(c git.Commit) string,
+) (map[string]bool, error) {
+	// Tally by name and email together so that we see every identity
+	tallies, err := tallyRepo(
+		ctx,
+		revs,
+		nil,
+		cmd.LogFilters{},
+		diffOpts,
+		ignoreRevsFiles,
+		false,
+		tally.TallyOpts{
+			Mode: tally.CommitMode,
+			Key: func(c git.Commit) string {
+				return c.AuthorName + "\x00" + c.AuthorEmail
+			},
+			CountMerges: true,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for _, t := range tallies {
+		final := t.Final()
+		for _, author := range authors {
+			if strings.EqualFold(author, final.AuthorName) ||
+				strings.EqualFold(author, final.AuthorEmail) {
+				keys[key(git.Commit{
+					AuthorName:  final.AuthorName,
+					AuthorEmail: final.AuthorEmail,
+				})] = true
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+func writeOrphansCsv(orphans []tally.Orphan) error {
+	w := csv.NewWriter(os.Stdout)
+
+	w.Write([]string{
+		"path",
+		"share",
+		"owned",
+		"total",
+		"owner name",
+		"owner email",
+		"suggested owner name",
+		"suggested owner email",
+	})
+
+	for _, o := range orphans {
+		var nextName, nextEmail string
+		if o.NextOwner != nil {
+			nextName = o.NextOwner.AuthorName
+			nextEmail = o.NextOwner.AuthorEmail
+		}
+
+		record := []string{
+			o.Path,
+			strconv.FormatFloat(o.Share(), 'f', 4, 64),
+			strconv.FormatInt(o.Owned, 10),
+			strconv.FormatInt(o.Total, 10),
+			o.Owner.AuthorName,
+			o.Owner.AuthorEmail,
+			nextName,
+			nextEmail,
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type ownerRecord struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type orphanRecord struct {
+	Path           string       `json:"path"`
+	Share          float64      `json:"share"`
+	Owned          int64        `json:"owned"`
+	Total          int64        `json:"total"`
+	Owner          ownerRecord  `json:"owner"`
+	SuggestedOwner *ownerRecord `json:"suggested_owner"`
+}
+
+func writeOrphansJson(orphans []tally.Orphan) error {
+	records := []orphanRecord{}
+	for _, o := range orphans {
+		record := orphanRecord{
+			Path:  o.Path,
+			Share: o.Share(),
+			Owned: o.Owned,
+			Total: o.Total,
+			Owner: ownerRecord{o.Owner.AuthorName, o.Owner.AuthorEmail},
+		}
+		if o.NextOwner != nil {
+			record.SuggestedOwner = &ownerRecord{
+				o.NextOwner.AuthorName,
+				o.NextOwner.AuthorEmail,
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writeOrphansTable(orphans []tally.Orphan, showEmail bool) {
+	if len(orphans) == 0 {
+		return
+	}
+
+	colwidth := wideWidth
+	ownerWidth := 18
+	nextWidth := 22
+	pathWidth := colwidth - 2 - 6 - 2 - ownerWidth - 2 - nextWidth
+
+	var build strings.Builder
+	for _ = range colwidth - 2 {
+		build.WriteRune('─')
+	}
+	rule := build.String()
+
+	fmt.Printf("┌%s┐\n", rule)
+	fmt.Printf(
+		"│%-*s %5s  %-*s  %-*s│\n",
+		pathWidth,
+		"Path",
+		"Share",
+		ownerWidth,
+		"Owner",
+		nextWidth,
+		"Suggested Owner",
+	)
+	fmt.Printf("├%s┤\n", rule)
+
+	totalRows := len(orphans)
+	for i, o := range orphans {
+		alternating := ""
+		if totalRows > maxBeforeColorAlternating && i%2 == 1 {
+			alternating = pretty.Invert
+		}
+
+		owner := o.Owner.AuthorName
+		next := "-"
+		if o.NextOwner != nil {
+			next = o.NextOwner.AuthorName
+		}
+		if showEmail {
+			owner = o.Owner.AuthorEmail
+			if o.NextOwner != nil {
+				next = o.NextOwner.AuthorEmail
+			}
+		}
+
+		p := format.Abbrev(o.Path, pathWidth)
+		owner = format.Abbrev(owner, ownerWidth)
+		next = format.Abbrev(next, nextWidth)
+
+		fmt.Printf(
+			"│%s%s %4.0f%%  %s  %s%s│\n",
+			alternating,
+			runewidth.FillRight(p, pathWidth),
+			o.Share()*100,
+			runewidth.FillRight(owner, ownerWidth),
+			runewidth.FillRight(next, nextWidth),
+			pretty.Reset,
+		)
+	}
+
+	fmt.Printf("└%s┘\n", rule)
+}
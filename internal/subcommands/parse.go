@@ -22,6 +22,9 @@ func Parse(
 	until string,
 	authors []string,
 	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
 ) (err error) {
 	defer func() {
 		if err != nil {
@@ -45,6 +48,12 @@ func Parse(
 		authors,
 		"nauthors",
 		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
 	)
 
 	start := time.Now()
@@ -53,10 +62,11 @@ func Parse(
 	defer cancel()
 
 	filters := cmd.LogFilters{
-		Since:    since,
-		Until:    until,
-		Authors:  authors,
-		Nauthors: nauthors,
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
 	}
 
 	gitRootPath, err := git.GetRoot()
@@ -64,7 +74,10 @@ func Parse(
 		return err
 	}
 
-	configFiles, err := config.DetectSupplementalFiles(gitRootPath)
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
 	if err != nil {
 		return err
 	}
@@ -75,6 +88,7 @@ func Parse(
 		pathspecs,
 		filters,
 		!short,
+		diffOpts,
 		configFiles,
 	)
 
@@ -83,6 +97,9 @@ func Parse(
 	numCommits := 0
 	for commit := range commits {
 		fmt.Fprintf(w, "%s\n", commit)
+		for _, trailer := range commit.Trailers {
+			fmt.Fprintf(w, "  %s\n", trailer)
+		}
 		for _, diff := range commit.FileDiffs {
 			fmt.Fprintf(w, "  %s\n", diff)
 		}
@@ -0,0 +1,287 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// Monday first, the way most people read a week
+var weekdays = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+type namedPunchcard struct {
+	author    string // Empty for all authors
+	punchcard tally.Punchcard
+}
+
+// The "punchcard" subcommand shows when commits are made, by day of the week
+// and hour of the day in each author's own time zone.
+//
+// There is one punchcard for all authors, or one for each author if any are
+// given. Authors are matched the same way as git log --author.
+func Punchcard(
+	revs []string,
+	pathspecs []string,
+	useCsv bool,
+	useJson bool,
+	countMerges bool,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"punchcard\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called punchcard()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"countMerges",
+		countMerges,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		CountMerges: countMerges,
+		FirstParent: firstParent,
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	// One pass over the log for each author so git does the matching
+	authorFilters := [][]string{nil}
+	if len(authors) > 0 {
+		authorFilters = [][]string{}
+		for _, author := range authors {
+			authorFilters = append(authorFilters, []string{author})
+		}
+	}
+
+	punchcards := []namedPunchcard{}
+	for _, authorFilter := range authorFilters {
+		filters := cmd.LogFilters{
+			Since:       since,
+			Until:       until,
+			Authors:     authorFilter,
+			Nauthors:    nauthors,
+			FirstParent: firstParent,
+		}
+
+		punchcard, err := func() (_ tally.Punchcard, err error) {
+			commits, finish := git.CommitsWithOpts(
+				ctx,
+				revs,
+				pathspecs,
+				filters,
+				false,
+				cmd.DiffOpts{},
+				configFiles,
+			)
+			defer func() { err = errors.Join(err, finish()) }()
+
+			return tally.TallyPunchcard(commits, tallyOpts), nil
+		}()
+		if err != nil {
+			return err
+		}
+
+		punchcards = append(punchcards, namedPunchcard{
+			author:    strings.Join(authorFilter, ""),
+			punchcard: punchcard,
+		})
+	}
+
+	if useCsv {
+		return writePunchcardsCsv(punchcards)
+	} else if useJson {
+		return writePunchcardsJson(punchcards)
+	}
+
+	for i, p := range punchcards {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		writePunchcard(p)
+	}
+
+	return nil
+}
+
+func writePunchcardsCsv(punchcards []namedPunchcard) error {
+	w := csv.NewWriter(os.Stdout)
+
+	w.Write([]string{"author", "weekday", "hour", "commits"})
+
+	for _, p := range punchcards {
+		for _, weekday := range weekdays {
+			for hour, n := range p.punchcard[weekday] {
+				record := []string{
+					p.author,
+					weekday.String(),
+					strconv.Itoa(hour),
+					strconv.Itoa(n),
+				}
+				if err := w.Write(record); err != nil {
+					return fmt.Errorf(
+						"error writing CSV record to stdout: %w",
+						err,
+					)
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type weekdayRecord struct {
+	Weekday string `json:"weekday"`
+	Hours   []int  `json:"hours"` // Commits in each hour, starting at midnight
+}
+
+type punchcardRecord struct {
+	Author   string          `json:"author"` // Empty for all authors
+	Commits  int             `json:"commits"`
+	Weekdays []weekdayRecord `json:"weekdays"`
+}
+
+func writePunchcardsJson(punchcards []namedPunchcard) error {
+	records := []punchcardRecord{}
+	for _, p := range punchcards {
+		record := punchcardRecord{
+			Author:   p.author,
+			Commits:  p.punchcard.Total(),
+			Weekdays: []weekdayRecord{},
+		}
+		for _, weekday := range weekdays {
+			record.Weekdays = append(record.Weekdays, weekdayRecord{
+				Weekday: weekday.String(),
+				Hours:   p.punchcard[weekday][:],
+			})
+		}
+
+		records = append(records, record)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writePunchcard(p namedPunchcard) {
+	title := "All authors"
+	if len(p.author) > 0 {
+		title = p.author
+	}
+
+	total := p.punchcard.Total()
+	fmt.Printf(
+		"%s%s%s (%s commits)\n",
+		pretty.Bold,
+		title,
+		pretty.Reset,
+		format.Number(total),
+	)
+	if total == 0 {
+		return
+	}
+
+	// Label every third hour; each hour is two columns wide
+	var header strings.Builder
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&header, "%02d    ", hour)
+	}
+	fmt.Printf("%-4s %s %7s\n", "", header.String(), "Commits")
+
+	maxCommits := p.punchcard.Max()
+	for _, weekday := range weekdays {
+		var row strings.Builder
+		dayTotal := 0
+		for _, n := range p.punchcard[weekday] {
+			dayTotal += n
+
+			if n == 0 {
+				row.WriteString(pretty.Dim + "· " + pretty.Reset)
+				continue
+			}
+
+			shade := string(format.Shade(n, maxCommits))
+			row.WriteString(pretty.Green + shade + shade + pretty.Reset)
+		}
+
+		fmt.Printf(
+			"%-4s %s %7s\n",
+			weekday.String()[:3],
+			row.String(),
+			format.Number(dayTotal),
+		)
+	}
+}
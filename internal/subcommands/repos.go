@@ -0,0 +1,286 @@
+package subcommands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+)
+
+// A repository given on the command line or listed in a manifest file.
+type Repo struct {
+	Path string
+	// Revisions and pathspecs for this repository. If nil, the revisions and
+	// pathspecs given on the command line apply.
+	Args []string
+}
+
+// Repositories to tally together in place of the repository in the working
+// directory.
+type RepoSet struct {
+	Repos       []Repo
+	Args        []string // Revisions and pathspecs given on the command line
+	MailmapPath string   // Mailmap applied in every repository, may be empty
+	// Set if MailmapPath is a temporary file we made, see SetMailmap()
+	isTempMailmap bool
+}
+
+func (s RepoSet) IsEmpty() bool {
+	return len(s.Repos) == 0
+}
+
+// Sets the mailmap applied in every repository.
+//
+// Git only reads one mailmap.file, so we combine the given mailmap with any
+// mailmap.file from the user's Git configuration in a temporary file. Entries
+// in the given mailmap come last so that they win. Call Close() to remove the
+// temporary file.
+func (s *RepoSet) SetMailmap(p string) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("could not read mailmap: %w", err)
+		}
+	}()
+
+	given, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+
+	configuredPath, err := config.ConfiguredMailmapPath(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(configuredPath) == 0 {
+		s.MailmapPath = p
+		return nil
+	}
+
+	configured, err := os.ReadFile(configuredPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.MailmapPath = p
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", "git-who-mailmap-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, b := range [][]byte{configured, []byte("\n"), given} {
+		_, err = f.Write(b)
+		if err != nil {
+			os.Remove(f.Name())
+			return err
+		}
+	}
+
+	logger().Debug(
+		"combined mailmaps",
+		"configured",
+		configuredPath,
+		"given",
+		p,
+		"combined",
+		f.Name(),
+	)
+
+	s.MailmapPath = f.Name()
+	s.isTempMailmap = true
+	return nil
+}
+
+// Removes any temporary file made by SetMailmap().
+func (s RepoSet) Close() error {
+	if s.isTempMailmap {
+		return os.Remove(s.MailmapPath)
+	}
+
+	return nil
+}
+
+// Context that applies the mailmap for the set to every Git subprocess.
+func (s RepoSet) context(ctx context.Context) context.Context {
+	if len(s.MailmapPath) > 0 {
+		return cmd.WithConfig(ctx, "mailmap.file", s.MailmapPath)
+	}
+
+	return ctx
+}
+
+// Reads a manifest file listing repositories to tally together.
+//
+// Each line names a repository, optionally followed by revisions and pathspecs
+// for that repository. Blank lines and lines starting with "#" are ignored.
+// Relative paths are relative to the directory containing the manifest.
+func ReadManifest(p string) (_ []Repo, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error reading manifest: %w", err)
+		}
+	}()
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	repos := []Repo{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		var repo Repo
+		repo.Path = fields[0]
+		if !filepath.IsAbs(repo.Path) {
+			repo.Path = filepath.Join(filepath.Dir(p), repo.Path)
+		}
+
+		if len(fields) > 1 {
+			repo.Args = fields[1:]
+		}
+
+		repos = append(repos, repo)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// Returns a target for each repository in the set, named after the directory
+// containing the repository.
+func findRepoTargets(
+	ctx context.Context,
+	set RepoSet,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+) ([]tallyTarget, error) {
+	targets := []tallyTarget{}
+	rootPaths := map[string]string{} // Name -> root path
+
+	for _, repo := range set.Repos {
+		target, err := repoTarget(ctx, repo, set.Args, ignoreRevsFiles)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not read repository \"%s\": %w",
+				repo.Path,
+				err,
+			)
+		}
+
+		if other, ok := rootPaths[target.path]; ok {
+			if other == target.rootPath {
+				return nil, fmt.Errorf(
+					"repository \"%s\" given more than once",
+					target.rootPath,
+				)
+			}
+
+			return nil, fmt.Errorf(
+				"repositories \"%s\" and \"%s\" have the same name",
+				other,
+				target.rootPath,
+			)
+		}
+		rootPaths[target.path] = target.rootPath
+
+		if recurseSubmodules {
+			withSubs, err := withSubmodules(ctx, target)
+			if err != nil {
+				return nil, err
+			}
+
+			targets = append(targets, withSubs...)
+		} else {
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, nil
+}
+
+func repoTarget(
+	ctx context.Context,
+	repo Repo,
+	defaultArgs []string,
+	ignoreRevsFiles []string,
+) (_ tallyTarget, err error) {
+	var target tallyTarget
+
+	absPath, err := filepath.Abs(repo.Path)
+	if err != nil {
+		return target, err
+	}
+
+	rootPath, err := git.GetRootContext(cmd.WithDir(ctx, absPath))
+	if err != nil {
+		return target, err
+	}
+
+	args := repo.Args
+	if args == nil {
+		args = defaultArgs
+	}
+
+	// Paths are relative to the directory given, like they would be if we ran
+	// git-who there
+	revs, pathspecs, err := git.ParseArgsContext(
+		cmd.WithDir(ctx, absPath),
+		args,
+	)
+	if err != nil {
+		return target, err
+	}
+
+	for _, p := range pathspecs {
+		if !git.IsSupportedPathspec(p) {
+			return target, fmt.Errorf("unsupported magic in pathspec: \"%s\"", p)
+		}
+	}
+
+	prefix, err := filepath.Rel(rootPath, absPath)
+	if err != nil {
+		return target, err
+	}
+
+	target = tallyTarget{
+		path:      filepath.Base(rootPath),
+		rootPath:  rootPath,
+		revs:      revs,
+		pathspecs: git.PathspecsFromRoot(pathspecs, filepath.ToSlash(prefix)),
+	}
+
+	target.configFiles, err = config.DetectSupplementalFilesContext(
+		target.context(ctx),
+		rootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return target, err
+	}
+
+	return target, nil
+}
@@ -0,0 +1,54 @@
+package subcommands
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/score"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// Parses the argument to -score, which is either an expression or the name of
+// a profile set with who.score.<name> in the git config.
+//
+// Profile names may contain "-", so "commits-files" could be either. We try it
+// as an expression first and only look for a profile if that fails.
+func parseScore(
+	ctx context.Context,
+	s string,
+) (_ func(t tally.FinalTally) float64, err error) {
+	f, parseErr := tally.ParseScore(s)
+	if parseErr == nil || !score.IsName(s) {
+		return f, parseErr
+	}
+
+	expr, err := config.ScoreProfile(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr == "" {
+		return nil, fmt.Errorf(
+			"no score profile named \"%s\"; set one with git config who.score.%s",
+			s,
+			s,
+		)
+	}
+
+	logger().Debug("using score profile", "name", s, "expr", expr)
+	return tally.ParseScore(expr)
+}
+
+// Formats a score with one decimal place, dropping the fraction for large
+// scores so they fit in a column.
+func formatScore(s float64) string {
+	if math.Abs(s) >= 1000 {
+		return format.Number(int(math.Round(s)))
+	}
+
+	return strconv.FormatFloat(s, 'f', 1, 64)
+}
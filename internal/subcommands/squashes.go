@@ -0,0 +1,92 @@
+package subcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/mailmap"
+)
+
+// Returns the co-authors of each squash commit in the repository in the
+// working directory, or in the given repositories if there are any, for use
+// as tally.TallyOpts.SquashCoAuthors.
+//
+// Co-authors are mapped through each repository's mailmap so that they match
+// the mapped authors of the commits we tally.
+func squashCoAuthors(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	filters cmd.LogFilters,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+) (map[string][]git.Author, error) {
+	ctx = repos.context(ctx)
+
+	targets, err := findTargets(
+		ctx,
+		revs,
+		pathspecs,
+		repos,
+		ignoreRevsFiles,
+		recurseSubmodules,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	coAuthors, err := tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			_ bool,
+		) (byCommit[[]git.Author], error) {
+			m, err := readMailmap(target.configFiles)
+			if err != nil {
+				return nil, err
+			}
+
+			coAuthors, err := git.SquashCoAuthors(
+				ctx,
+				target.revs,
+				target.pathspecs,
+				filters,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			for hash, authors := range coAuthors {
+				coAuthors[hash] = m.MapAuthors(authors)
+			}
+
+			return coAuthors, nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split squash commits: %w", err)
+	}
+
+	return coAuthors, nil
+}
+
+// Reads the mailmap files Git would apply in a repository, in the order Git
+// reads them. Lines that can't be parsed are skipped, as Git skips them.
+func readMailmap(configFiles config.SupplementalFiles) (mailmap.Mailmap, error) {
+	paths := []string{}
+	if len(configFiles.RepoMailmapPath) > 0 {
+		paths = append(paths, configFiles.RepoMailmapPath)
+	}
+	if len(configFiles.GlobalMailmapPath) > 0 {
+		paths = append(paths, configFiles.GlobalMailmapPath)
+	}
+
+	m, _, err := mailmap.ReadFiles(paths...)
+	return m, err
+}
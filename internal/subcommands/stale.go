@@ -0,0 +1,265 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+	"github.com/sinclairtarget/git-who/internal/utils/timeutils"
+)
+
+// The "stale" subcommand lists the working-tree paths whose top contributors
+// have not committed anywhere in the repo since inactiveSince ago.
+//
+// Like tree, authors are told apart by name unless showEmail is set.
+func Stale(
+	revs []string,
+	pathspecs []string,
+	inactiveSince string,
+	mode tally.TallyMode,
+	useCsv bool,
+	useJson bool,
+	showEmail bool,
+	followRenames bool,
+	limit int,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"stale\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called stale()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"inactiveSince",
+		inactiveSince,
+		"mode",
+		mode,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"showEmail",
+		showEmail,
+		"followRenames",
+		followRenames,
+		"limit",
+		limit,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cutoff, err := timeutils.Ago(progStart, inactiveSince)
+	if err != nil {
+		return err
+	}
+
+	key := func(c git.Commit) string { return c.AuthorName }
+	if showEmail {
+		key = func(c git.Commit) string { return c.AuthorEmail }
+	}
+
+	lastActive, err := tallyLastActive(ctx, revs, diffOpts, ignoreRevsFiles, key)
+	if err != nil {
+		return err
+	}
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	tallyOpts := tally.TallyOpts{
+		Mode:          mode,
+		Key:           key,
+		FollowRenames: followRenames,
+		FirstParent:   firstParent,
+	}
+
+	root, err := tallyRepoTree(
+		ctx,
+		revs,
+		pathspecs,
+		filters,
+		diffOpts,
+		ignoreRevsFiles,
+		false,
+		tallyOpts,
+	)
+	if errors.Is(err, tally.EmptyTreeErr) {
+		logger().Debug("Tree was empty.")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	stale := root.Rank(mode).Stale(cutoff, func(t tally.FinalTally) time.Time {
+		if showEmail {
+			return lastActive[t.AuthorEmail]
+		}
+
+		return lastActive[t.AuthorName]
+	})
+	if limit > 0 && limit < len(stale) {
+		stale = stale[:limit]
+	}
+
+	if useCsv {
+		return writeStaleCsv(stale)
+	} else if useJson {
+		return writeStaleJson(stale)
+	}
+
+	writeStaleTable(stale, showEmail)
+	return nil
+}
+
+func writeStaleCsv(stale []tally.StalePath) error {
+	w := csv.NewWriter(os.Stdout)
+
+	w.Write([]string{
+		"path",
+		"owner name",
+		"owner email",
+		"last active time",
+	})
+
+	for _, s := range stale {
+		record := []string{
+			s.Path,
+			s.Owner.AuthorName,
+			s.Owner.AuthorEmail,
+			s.LastActive.Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type staleRecord struct {
+	Path       string      `json:"path"`
+	Owner      ownerRecord `json:"owner"`
+	LastActive time.Time   `json:"last_active_time"`
+}
+
+func writeStaleJson(stale []tally.StalePath) error {
+	records := []staleRecord{}
+	for _, s := range stale {
+		records = append(records, staleRecord{
+			Path:       s.Path,
+			Owner:      ownerRecord{s.Owner.AuthorName, s.Owner.AuthorEmail},
+			LastActive: s.LastActive,
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writeStaleTable(stale []tally.StalePath, showEmail bool) {
+	if len(stale) == 0 {
+		return
+	}
+
+	colwidth := wideWidth
+	ownerWidth := 22
+	pathWidth := colwidth - 2 - 1 - ownerWidth - 2 - 10 - 1 - 11
+
+	var build strings.Builder
+	for _ = range colwidth - 2 {
+		build.WriteRune('─')
+	}
+	rule := build.String()
+
+	fmt.Printf("┌%s┐\n", rule)
+	fmt.Printf(
+		"│%-*s %-*s  %-22s│\n",
+		pathWidth,
+		"Path",
+		ownerWidth,
+		"Top Contributor",
+		"Last Active",
+	)
+	fmt.Printf("├%s┤\n", rule)
+
+	totalRows := len(stale)
+	for i, s := range stale {
+		alternating := ""
+		if totalRows > maxBeforeColorAlternating && i%2 == 1 {
+			alternating = pretty.Invert
+		}
+
+		p := format.Abbrev(s.Path, pathWidth)
+		owner := s.Owner.AuthorName
+		if showEmail {
+			owner = s.Owner.AuthorEmail
+		}
+		owner = format.Abbrev(owner, ownerWidth)
+
+		fmt.Printf(
+			"│%s%s %s  %s %-11s%s│\n",
+			alternating,
+			runewidth.FillRight(p, pathWidth),
+			runewidth.FillRight(owner, ownerWidth),
+			s.LastActive.Local().Format(time.DateOnly),
+			format.RelativeTime(progStart, s.LastActive),
+			pretty.Reset,
+		)
+	}
+
+	fmt.Printf("└%s┘\n", rule)
+}
@@ -0,0 +1,418 @@
+package subcommands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	runewidth "github.com/mattn/go-runewidth"
+
+	"github.com/sinclairtarget/git-who/internal/format"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// Name of the scope covering every path
+const allScope = "(all)"
+
+// Name of the scope covering files not in any directory
+const topLevelFilesScope = "(top-level files)"
+
+type scopedConcentration struct {
+	scope string
+	tally.Concentration
+}
+
+// The "stats" subcommand summarizes how concentrated contributions are among
+// authors, over the whole repository and per top-level directory, or per
+// time period.
+func Stats(
+	revs []string,
+	pathspecs []string,
+	mode tally.TallyMode,
+	showEmail bool,
+	useCsv bool,
+	useJson bool,
+	overTime bool,
+	countMerges bool,
+	since string,
+	until string,
+	authors []string,
+	nauthors []string,
+	firstParent bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error running \"stats\": %w", err)
+		}
+	}()
+
+	logger().Debug(
+		"called stats()",
+		"revs",
+		revs,
+		"pathspecs",
+		pathspecs,
+		"mode",
+		mode,
+		"showEmail",
+		showEmail,
+		"useCsv",
+		useCsv,
+		"useJson",
+		useJson,
+		"overTime",
+		overTime,
+		"countMerges",
+		countMerges,
+		"since",
+		since,
+		"until",
+		until,
+		"authors",
+		authors,
+		"nauthors",
+		nauthors,
+		"firstParent",
+		firstParent,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tallyOpts := tally.TallyOpts{
+		Mode:        mode,
+		CountMerges: countMerges,
+		FirstParent: firstParent,
+	}
+	if showEmail {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
+	} else {
+		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
+	}
+
+	filters := cmd.LogFilters{
+		Since:       since,
+		Until:       until,
+		Authors:     authors,
+		Nauthors:    nauthors,
+		FirstParent: firstParent,
+	}
+
+	var stats []scopedConcentration
+	if overTime {
+		stats, err = statsOverTime(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			tallyOpts,
+		)
+	} else {
+		stats, err = statsByDir(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			tallyOpts,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	if useCsv {
+		return writeStatsCsv(stats, overTime)
+	} else if useJson {
+		return writeStatsJson(stats, overTime)
+	}
+
+	writeStats(stats, overTime)
+	return nil
+}
+
+// Returns the first path component of the given path with a trailing slash.
+func topLevelDir(p string) string {
+	dir, _, found := strings.Cut(p, "/")
+	if !found {
+		return topLevelFilesScope
+	}
+
+	return dir + "/"
+}
+
+// Measures concentration over all paths and then for each top-level directory.
+func statsByDir(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	tallyOpts tally.TallyOpts,
+) ([]scopedConcentration, error) {
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := workingDirTarget(revs, pathspecs, gitRootPath, configFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath, err := tallyTargetsByPath(
+		ctx,
+		[]tallyTarget{target},
+		filters,
+		diffOpts,
+		tallyOpts,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := tallyOpts.Mode
+	stats := []scopedConcentration{
+		scopedConcentration{
+			scope: allScope,
+			Concentration: tally.MeasureConcentration(
+				tally.Rank(byPath.Reduce(), mode),
+				mode,
+			),
+		},
+	}
+
+	for _, g := range byPath.Groups(topLevelDir, mode) {
+		stats = append(stats, scopedConcentration{
+			scope:         g.Group,
+			Concentration: tally.MeasureConcentration(g.Authors, mode),
+		})
+	}
+
+	return stats, nil
+}
+
+// Measures concentration for each time period in the same buckets that the
+// "hist" subcommand uses.
+func statsOverTime(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	tallyOpts tally.TallyOpts,
+) ([]scopedConcentration, error) {
+	var end time.Time // Default is zero time, meaning use last commit
+	if len(revs) == 1 && revs[0] == "HEAD" && len(filters.Until) == 0 {
+		// If no revs or --until given, end timeline at current time
+		end = time.Now()
+	}
+
+	buckets, err := tallyRepoTimeline(
+		ctx,
+		revs,
+		pathspecs,
+		filters,
+		diffOpts,
+		ignoreRevsFiles,
+		tallyOpts,
+		end,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := tallyOpts.Mode
+	stats := []scopedConcentration{}
+	for _, bucket := range buckets {
+		stats = append(stats, scopedConcentration{
+			scope: bucket.Name,
+			Concentration: tally.MeasureConcentration(
+				bucket.Ranked(mode),
+				mode,
+			),
+		})
+	}
+
+	return stats, nil
+}
+
+func formatRatio(r float64) string {
+	return strconv.FormatFloat(r, 'f', 3, 64)
+}
+
+func writeStatsCsv(stats []scopedConcentration, overTime bool) error {
+	w := csv.NewWriter(os.Stdout)
+
+	scopeHeader := "scope"
+	if overTime {
+		scopeHeader = "period"
+	}
+
+	w.Write([]string{
+		scopeHeader,
+		"authors",
+		"total",
+		"gini",
+		"hhi",
+		"top 1 share",
+		"top 5 share",
+		"bus factor",
+	})
+
+	for _, s := range stats {
+		record := []string{
+			s.scope,
+			strconv.Itoa(s.Authors),
+			strconv.FormatInt(s.Total, 10),
+			formatRatio(s.Gini),
+			formatRatio(s.HHI),
+			strconv.FormatFloat(s.Top1Share, 'f', 2, 64),
+			strconv.FormatFloat(s.Top5Share, 'f', 2, 64),
+			strconv.Itoa(s.BusFactor),
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV record to stdout: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	return nil
+}
+
+type statsRecord struct {
+	Scope     string  `json:"scope,omitempty"`
+	Period    string  `json:"period,omitempty"`
+	Authors   int     `json:"authors"`
+	Total     int64   `json:"total"`
+	Gini      float64 `json:"gini"`
+	HHI       float64 `json:"hhi"`
+	Top1Share float64 `json:"top_1_share"`
+	Top5Share float64 `json:"top_5_share"`
+	BusFactor int     `json:"bus_factor"`
+}
+
+func writeStatsJson(stats []scopedConcentration, overTime bool) error {
+	records := []statsRecord{}
+	for _, s := range stats {
+		record := statsRecord{
+			Authors:   s.Authors,
+			Total:     s.Total,
+			Gini:      s.Gini,
+			HHI:       s.HHI,
+			Top1Share: s.Top1Share,
+			Top5Share: s.Top5Share,
+			BusFactor: s.BusFactor,
+		}
+		if overTime {
+			record.Period = s.scope
+		} else {
+			record.Scope = s.scope
+		}
+
+		records = append(records, record)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return fmt.Errorf("error writing JSON to stdout: %w", err)
+	}
+
+	return nil
+}
+
+func writeStats(stats []scopedConcentration, overTime bool) {
+	if len(stats) == 0 {
+		return
+	}
+
+	scopeWidth := 24
+	scopeHeader := "Scope"
+	if overTime {
+		scopeWidth = 12
+		scopeHeader = "Period"
+	}
+
+	fmt.Printf(
+		"%-*s %7s %6s %6s %7s %7s %10s\n",
+		scopeWidth,
+		scopeHeader,
+		"Authors",
+		"Gini",
+		"HHI",
+		"Top 1",
+		"Top 5",
+		"Bus Factor",
+	)
+
+	for i, s := range stats {
+		scope := runewidth.FillRight(format.Abbrev(s.scope, scopeWidth), scopeWidth)
+
+		style := ""
+		if !overTime && i == 0 {
+			style = pretty.Bold // Overall row
+		}
+
+		if s.Authors == 0 {
+			fmt.Printf(
+				"%s%s%s %7s %6s %6s %7s %7s %10s\n",
+				pretty.Dim,
+				scope,
+				pretty.Reset,
+				"0",
+				"-",
+				"-",
+				"-",
+				"-",
+				"-",
+			)
+			continue
+		}
+
+		fmt.Printf(
+			"%s%s %7s %6.2f %6.2f %7s %7s %10s%s\n",
+			style,
+			scope,
+			format.Number(s.Authors),
+			s.Gini,
+			s.HHI,
+			format.Percent(s.Top1Share),
+			format.Percent(s.Top5Share),
+			format.Number(s.BusFactor),
+			pretty.Reset,
+		)
+	}
+}
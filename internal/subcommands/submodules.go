@@ -0,0 +1,169 @@
+package subcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+)
+
+// Returns the repository in the working directory followed by every
+// checked-out submodule (and nested submodule) recorded in the given revisions.
+func findSubmoduleTargets(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	gitRootPath string,
+	configFiles config.SupplementalFiles,
+) ([]tallyTarget, error) {
+	superproject, err := workingDirTarget(
+		revs,
+		pathspecs,
+		gitRootPath,
+		configFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return withSubmodules(ctx, superproject)
+}
+
+// Returns a target for the repository in the working directory. Pathspecs are
+// made relative to the root of the working tree.
+func workingDirTarget(
+	revs []string,
+	pathspecs []string,
+	gitRootPath string,
+	configFiles config.SupplementalFiles,
+) (tallyTarget, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return tallyTarget{}, err
+	}
+
+	prefix, err := filepath.Rel(gitRootPath, wd)
+	if err != nil {
+		return tallyTarget{}, err
+	}
+
+	return tallyTarget{
+		rootPath:    gitRootPath,
+		revs:        revs,
+		pathspecs:   git.PathspecsFromRoot(pathspecs, filepath.ToSlash(prefix)),
+		configFiles: configFiles,
+	}, nil
+}
+
+// Returns the given target followed by the targets for its submodules.
+func withSubmodules(
+	ctx context.Context,
+	target tallyTarget,
+) ([]tallyTarget, error) {
+	submodules, err := submoduleTargets(ctx, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Concat([]tallyTarget{target}, submodules), nil
+}
+
+// Recursively finds the submodules of parent that we can tally.
+//
+// Records the paths of those submodules in parent.gitlinks.
+func submoduleTargets(
+	ctx context.Context,
+	parent *tallyTarget,
+) ([]tallyTarget, error) {
+	submodules, err := git.Submodules(parent.context(ctx), parent.revs)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []tallyTarget{}
+	for _, submodule := range submodules {
+		targetPath := path.Join(parent.path, submodule.Path)
+		rootPath := filepath.Join(
+			parent.rootPath,
+			filepath.FromSlash(submodule.Path),
+		)
+
+		_, err := os.Stat(filepath.Join(rootPath, ".git"))
+		if errors.Is(err, os.ErrNotExist) {
+			logger().Debug("skipping submodule not checked out", "path", targetPath)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		pathspecs, ok, err := git.SubmodulePathspecs(
+			parent.pathspecs,
+			submodule.Path,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			logger().Debug("skipping submodule outside pathspecs", "path", targetPath)
+			continue
+		}
+
+		subCtx := cmd.WithDir(ctx, rootPath)
+
+		revs, err := git.ExistingCommits(subCtx, submodule.Revs)
+		if err != nil {
+			return nil, err
+		}
+		if len(revs) < len(submodule.Revs) {
+			logger().Warn(
+				fmt.Sprintf(
+					"submodule \"%s\" is missing recorded commits; try git submodule update",
+					targetPath,
+				),
+			)
+		}
+
+		included := slices.ContainsFunc(revs, func(r string) bool {
+			return !strings.HasPrefix(r, "^")
+		})
+		if !included {
+			continue
+		}
+
+		configFiles, err := config.DetectSupplementalFilesContext(
+			subCtx,
+			rootPath,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		target := tallyTarget{
+			path:        targetPath,
+			rootPath:    rootPath,
+			revs:        revs,
+			pathspecs:   pathspecs,
+			configFiles: configFiles,
+		}
+
+		nested, err := submoduleTargets(ctx, &target)
+		if err != nil {
+			return nil, err
+		}
+
+		parent.gitlinks = append(parent.gitlinks, submodule.Path)
+		targets = append(targets, target)
+		targets = append(targets, nested...)
+	}
+
+	return targets, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -24,10 +25,14 @@ import (
 
 const narrowWidth = 55
 const wideWidth = 80
+const shareWidth = 14 // Width of the share and cumulative share columns
 const maxBeforeColorAlternating = 14
 
 func pickWidth(mode tally.TallyMode, showEmail bool) int {
-	wideMode := mode == tally.FilesMode || mode == tally.LinesMode
+	wideMode := mode == tally.FilesMode ||
+		mode.IsLinesMode() ||
+		mode == tally.ActiveDaysMode ||
+		mode == tally.ScoreMode
 	if wideMode || showEmail {
 		return wideWidth
 	}
@@ -40,15 +45,28 @@ func pickWidth(mode tally.TallyMode, showEmail bool) int {
 func Table(
 	revs []string,
 	pathspecs []string,
+	repos RepoSet,
 	mode tally.TallyMode,
+	scoreExpr string,
 	useCsv bool,
 	showEmail bool,
+	showPct bool,
 	countMerges bool,
+	recurseSubmodules bool,
+	identity tally.IdentityMode,
+	autoMerge bool,
+	splitSquashes bool,
+	dateMode tally.DateMode,
 	limit int,
 	since string,
 	until string,
 	authors []string,
 	nauthors []string,
+	omitCherryPicks bool,
+	firstParent bool,
+	creditBranchAuthor bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
 ) (err error) {
 	defer func() {
 		if err != nil {
@@ -62,14 +80,30 @@ func Table(
 		revs,
 		"pathspecs",
 		pathspecs,
+		"repos",
+		repos,
 		"mode",
 		mode,
+		"scoreExpr",
+		scoreExpr,
 		"useCsv",
 		useCsv,
 		"showEmail",
 		showEmail,
+		"showPct",
+		showPct,
 		"countMerges",
 		countMerges,
+		"recurseSubmodules",
+		recurseSubmodules,
+		"identity",
+		identity,
+		"autoMerge",
+		autoMerge,
+		"splitSquashes",
+		splitSquashes,
+		"dateMode",
+		dateMode,
 		"limit",
 		limit,
 		"since",
@@ -80,96 +114,258 @@ func Table(
 		authors,
 		"nauthors",
 		nauthors,
+		"omitCherryPicks",
+		omitCherryPicks,
+		"firstParent",
+		firstParent,
+		"creditBranchAuthor",
+		creditBranchAuthor,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tallyOpts := tally.TallyOpts{Mode: mode, CountMerges: countMerges}
+	tallyOpts := tally.TallyOpts{
+		Mode:        mode,
+		CountMerges: countMerges,
+		Identity:    identity,
+		Date:        dateMode,
+		FirstParent: firstParent,
+	}
 	if showEmail {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
 	} else {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
 	}
 
-	populateDiffs := tallyOpts.IsDiffMode()
+	if mode == tally.ScoreMode {
+		tallyOpts.Score, err = parseScore(ctx, scoreExpr)
+		if err != nil {
+			return err
+		}
+	}
+
 	filters := cmd.LogFilters{
-		Since:    since,
-		Until:    until,
-		Authors:  authors,
-		Nauthors: nauthors,
+		Since:           since,
+		Until:           until,
+		Authors:         authors,
+		Nauthors:        nauthors,
+		OmitCherryPicks: omitCherryPicks,
+		FirstParent:     firstParent,
 	}
 
-	gitRootPath, err := git.GetRoot()
-	if err != nil {
-		return err
+	if creditBranchAuthor {
+		tallyOpts.BranchAuthors, err = branchAuthors(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			ignoreRevsFiles,
+			recurseSubmodules,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	configFiles, err := config.DetectSupplementalFiles(gitRootPath)
-	if err != nil {
-		return err
+	if splitSquashes {
+		tallyOpts.SquashCoAuthors, err = squashCoAuthors(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			filters,
+			ignoreRevsFiles,
+			recurseSubmodules,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	var tallies map[string]tally.Tally
-	if populateDiffs && runtime.GOMAXPROCS(0) > 1 {
-		tallies, err = concurrent.TallyCommits(
+	if autoMerge {
+		tallyOpts.Merge, err = mergeIdentities(
 			ctx,
 			revs,
 			pathspecs,
+			repos,
 			filters,
-			configFiles,
-			tallyOpts,
-			cache.GetCache(gitRootPath, configFiles),
-			pretty.AllowDynamic(os.Stdout),
+			ignoreRevsFiles,
+			recurseSubmodules,
+			identity,
 		)
 		if err != nil {
 			return err
 		}
-	} else {
-		err = func() (err error) {
-			// This is fast in the no-diff case even if we don't parallelize it
-			commits, finish := git.CommitsWithOpts(
-				ctx,
-				revs,
-				pathspecs,
-				filters,
-				populateDiffs,
-				configFiles,
-			)
-			defer func() { err = finish() }()
+	}
+
+	var tallies map[string]tally.Tally
+	if !repos.IsEmpty() {
+		ctx := repos.context(ctx)
 
-			tallies, err = tally.TallyCommits(commits, tallyOpts)
+		targets, err := findRepoTargets(
+			ctx,
+			repos,
+			ignoreRevsFiles,
+			recurseSubmodules,
+		)
+		if err != nil {
 			return err
-		}()
+		}
 
+		tallies, err = tallyTargets(ctx, targets, filters, diffOpts, tallyOpts)
 		if err != nil {
-			return fmt.Errorf("failed to tally commits: %w", err)
+			return err
+		}
+	} else {
+		tallies, err = tallyRepo(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			recurseSubmodules,
+			tallyOpts,
+		)
+		if err != nil {
+			return err
 		}
 	}
 
 	rankedTallies := tally.Rank(tallies, mode)
 
+	// Shares are of the total before we apply the limit
+	var shares []tally.Share
+	if showPct {
+		shares = tally.Shares(rankedTallies, mode)
+	}
+
 	numFilteredOut := 0
 	if limit > 0 && limit < len(rankedTallies) {
 		numFilteredOut = len(rankedTallies) - limit
 		rankedTallies = rankedTallies[:limit]
+		if showPct {
+			shares = shares[:limit]
+		}
 	}
 
 	if useCsv {
-		err := writeCsv(rankedTallies, tallyOpts, showEmail)
+		err := writeCsv(rankedTallies, shares, tallyOpts, showEmail)
 		if err != nil {
 			return err
 		}
 	} else {
+		header := "Author"
+		if identity == tally.ReviewerIdentity {
+			header = "Reviewer"
+		} else if identity == tally.ExtensionIdentity {
+			header = "Extension"
+		}
+
 		colwidth := pickWidth(mode, showEmail)
-		writeTable(rankedTallies, colwidth, showEmail, mode, numFilteredOut)
+		if showPct {
+			colwidth += shareWidth
+		}
+
+		writeTable(
+			rankedTallies,
+			shares,
+			header,
+			colwidth,
+			showEmail,
+			mode,
+			numFilteredOut,
+		)
 	}
 
 	return nil
 }
 
+// Tallies commits in the repository in the working directory.
+func tallyRepo(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+	tallyOpts tally.TallyOpts,
+) (tallies map[string]tally.Tally, err error) {
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	populateDiffs := tallyOpts.IsDiffMode()
+
+	if recurseSubmodules {
+		targets, err := findSubmoduleTargets(
+			ctx,
+			revs,
+			pathspecs,
+			gitRootPath,
+			configFiles,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return tallyTargets(ctx, targets, filters, diffOpts, tallyOpts)
+	} else if populateDiffs && runtime.GOMAXPROCS(0) > 1 {
+		return concurrent.TallyCommits(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			configFiles,
+			tallyOpts,
+			cache.GetCache(gitRootPath, configFiles, diffOpts),
+			pretty.AllowDynamic(os.Stdout),
+		)
+	}
+
+	err = func() (err error) {
+		// This is fast in the no-diff case even if we don't parallelize it
+		commits, finish := git.CommitsWithOpts(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			populateDiffs,
+			diffOpts,
+			configFiles,
+		)
+		defer func() { err = finish() }()
+
+		tallies, err = tally.TallyCommits(commits, tallyOpts)
+		return err
+	}()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to tally commits: %w", err)
+	}
+
+	return tallies, nil
+}
+
 func toRecord(
 	t tally.FinalTally,
+	share *tally.Share,
 	opts tally.TallyOpts,
 	showEmail bool,
 ) []string {
@@ -190,15 +386,41 @@ func toRecord(
 		)
 	}
 
-	return append(
+	if opts.Mode == tally.ScoreMode {
+		record = append(record, strconv.FormatFloat(t.Score, 'f', 2, 64))
+	}
+
+	if opts.Mode == tally.ActiveDaysMode {
+		record = append(
+			record,
+			strconv.Itoa(t.ActiveDays),
+			strconv.Itoa(t.ActiveWeeks),
+			strconv.Itoa(t.LongestStreak),
+			strconv.FormatFloat(t.MedianGap, 'f', -1, 64),
+		)
+	}
+
+	record = append(
 		record,
 		t.LastCommitTime.Format(time.RFC3339),
 		t.FirstCommitTime.Format(time.RFC3339),
 	)
+
+	if share != nil {
+		record = append(
+			record,
+			strconv.FormatFloat(share.Percent, 'f', 2, 64),
+			strconv.FormatFloat(share.Cumulative, 'f', 2, 64),
+		)
+	}
+
+	return record
 }
 
+// Shares may be nil, in which case the share columns are left out.
 func writeCsv(
 	tallies []tally.FinalTally,
+	shares []tally.Share,
 	opts tally.TallyOpts,
 	showEmail bool,
 ) error {
@@ -221,11 +443,33 @@ func writeCsv(
 		)
 	}
 
+	if opts.Mode == tally.ScoreMode {
+		columnHeaders = append(columnHeaders, "score")
+	}
+
+	if opts.Mode == tally.ActiveDaysMode {
+		columnHeaders = append(
+			columnHeaders,
+			"active days",
+			"active weeks",
+			"longest streak",
+			"median days between active days",
+		)
+	}
+
 	columnHeaders = append(columnHeaders, "last commit time", "first commit time")
+	if shares != nil {
+		columnHeaders = append(columnHeaders, "share", "cumulative share")
+	}
 	w.Write(columnHeaders)
 
-	for _, tally := range tallies {
-		record := toRecord(tally, opts, showEmail)
+	for i, t := range tallies {
+		var share *tally.Share
+		if shares != nil {
+			share = &shares[i]
+		}
+
+		record := toRecord(t, share, opts, showEmail)
 		if err := w.Write(record); err != nil {
 			return fmt.Errorf("error writing CSV record to stdout: %w", err)
 		}
@@ -260,8 +504,11 @@ func formatAuthor(
 	return runewidth.FillRight(author, width)
 }
 
+// Shares may be nil, in which case the share columns are left out.
 func writeTable(
 	tallies []tally.FinalTally,
+	shares []tally.Share,
+	header string,
 	colwidth int,
 	showEmail bool,
 	mode tally.TallyMode,
@@ -277,34 +524,75 @@ func writeTable(
 	}
 	rule := build.String()
 
+	// Width of the columns before the share columns
+	width := colwidth
+	shareHeader := ""
+	if shares != nil {
+		width -= shareWidth
+		shareHeader = fmt.Sprintf(" %6s %6s", "Share", "Cum.")
+	}
+
 	// -- Write header --
 	fmt.Printf("┌%s┐\n", rule)
 
-	if mode == tally.LinesMode || mode == tally.FilesMode {
+	if mode == tally.NetLinesMode || mode == tally.ScoreMode {
+		extraHeader := "Net"
+		if mode == tally.ScoreMode {
+			extraHeader = "Score"
+		}
+
 		fmt.Printf(
-			"│%-*s %-11s %7s %7s  %17s│\n",
-			colwidth-36-13,
-			"Author",
+			"│%-*s %-11s %7s %7s  %17s %8s%s│\n",
+			width-36-13-9,
+			header,
 			"Last Edit",
 			"Commits",
 			"Files",
 			"Lines (+/-)",
+			extraHeader,
+			shareHeader,
+		)
+	} else if mode.IsLinesMode() || mode == tally.FilesMode {
+		fmt.Printf(
+			"│%-*s %-11s %7s %7s  %17s%s│\n",
+			width-36-13,
+			header,
+			"Last Edit",
+			"Commits",
+			"Files",
+			"Lines (+/-)",
+			shareHeader,
+		)
+	} else if mode == tally.ActiveDaysMode {
+		fmt.Printf(
+			"│%-*s %-11s %7s %6s %6s %7s %6s%s│\n",
+			width-51,
+			header,
+			"Last Edit",
+			"Commits",
+			"Days",
+			"Weeks",
+			"Streak",
+			"Gap",
+			shareHeader,
 		)
 	} else if mode == tally.FirstModifiedMode {
 		fmt.Printf(
-			"│%-*s %-11s %7s│\n",
-			colwidth-22,
-			"Author",
+			"│%-*s %-11s %7s%s│\n",
+			width-22,
+			header,
 			"First Edit",
 			"Commits",
+			shareHeader,
 		)
 	} else {
 		fmt.Printf(
-			"│%-*s %-11s %7s│\n",
-			colwidth-22,
-			"Author",
+			"│%-*s %-11s %7s%s│\n",
+			width-22,
+			header,
 			"Last Edit",
 			"Commits",
+			shareHeader,
 		)
 	}
 	fmt.Printf("├%s┤\n", rule)
@@ -317,6 +605,15 @@ func writeTable(
 			alternating = pretty.Invert
 		}
 
+		shareCols := ""
+		if shares != nil {
+			shareCols = fmt.Sprintf(
+				" %6s %6s",
+				format.Percent(shares[i].Percent),
+				format.Percent(shares[i].Cumulative),
+			)
+		}
+
 		lines := fmt.Sprintf(
 			"%s%7s%s / %s%7s%s",
 			pretty.Green,
@@ -327,33 +624,68 @@ func writeTable(
 			pretty.DefaultColor,
 		)
 
-		if mode == tally.LinesMode || mode == tally.FilesMode {
+		if mode == tally.NetLinesMode || mode == tally.ScoreMode {
+			extraCol := formatNetLines(t, 8)
+			if mode == tally.ScoreMode {
+				extraCol = fmt.Sprintf("%8s", formatScore(t.Score))
+			}
+
 			fmt.Printf(
-				"│%s%s %-11s %7s %7s  %17s%s│\n",
+				"│%s%s %-11s %7s %7s  %17s %s%s%s│\n",
 				alternating,
-				formatAuthor(t, showEmail, colwidth-36-13),
+				formatAuthor(t, showEmail, width-36-13-9),
 				format.RelativeTime(progStart, t.LastCommitTime),
 				format.Number(t.Commits),
 				format.Number(t.FileCount),
 				lines,
+				extraCol,
+				shareCols,
+				pretty.Reset,
+			)
+		} else if mode.IsLinesMode() || mode == tally.FilesMode {
+			fmt.Printf(
+				"│%s%s %-11s %7s %7s  %17s%s%s│\n",
+				alternating,
+				formatAuthor(t, showEmail, width-36-13),
+				format.RelativeTime(progStart, t.LastCommitTime),
+				format.Number(t.Commits),
+				format.Number(t.FileCount),
+				lines,
+				shareCols,
+				pretty.Reset,
+			)
+		} else if mode == tally.ActiveDaysMode {
+			fmt.Printf(
+				"│%s%s %-11s %7s %6s %6s %7s %6s%s%s│\n",
+				alternating,
+				formatAuthor(t, showEmail, width-51),
+				format.RelativeTime(progStart, t.LastCommitTime),
+				format.Number(t.Commits),
+				format.Number(t.ActiveDays),
+				format.Number(t.ActiveWeeks),
+				format.Number(t.LongestStreak),
+				formatGap(t),
+				shareCols,
 				pretty.Reset,
 			)
 		} else if mode == tally.FirstModifiedMode {
 			fmt.Printf(
-				"│%s%s %-11s %7s%s│\n",
+				"│%s%s %-11s %7s%s%s│\n",
 				alternating,
-				formatAuthor(t, showEmail, colwidth-22),
+				formatAuthor(t, showEmail, width-22),
 				format.RelativeTime(progStart, t.FirstCommitTime),
 				format.Number(t.Commits),
+				shareCols,
 				pretty.Reset,
 			)
 		} else {
 			fmt.Printf(
-				"│%s%s %-11s %7s%s│\n",
+				"│%s%s %-11s %7s%s%s│\n",
 				alternating,
-				formatAuthor(t, showEmail, colwidth-22),
+				formatAuthor(t, showEmail, width-22),
 				format.RelativeTime(progStart, t.LastCommitTime),
 				format.Number(t.Commits),
+				shareCols,
 				pretty.Reset,
 			)
 		}
@@ -361,8 +693,50 @@ func writeTable(
 
 	if numFilteredOut > 0 {
 		msg := fmt.Sprintf("...%s more...", format.Number(numFilteredOut))
+		if shares != nil {
+			// The rest of the total belongs to the authors we left out
+			restShare := 100 - shares[len(shares)-1].Cumulative
+			msg = fmt.Sprintf(
+				"...%s more (%s)...",
+				format.Number(numFilteredOut),
+				format.Percent(restShare),
+			)
+		}
 		fmt.Printf("│%-*s│\n", colwidth-2, msg)
 	}
 
 	fmt.Printf("└%s┘\n", rule)
 }
+
+// Median gap between active days, or a dash if there is only one active day
+func formatGap(t tally.FinalTally) string {
+	if t.ActiveDays < 2 {
+		return "-"
+	}
+
+	if t.MedianGap != math.Trunc(t.MedianGap) {
+		return strconv.FormatFloat(t.MedianGap, 'f', 1, 64) + "d"
+	}
+
+	return fmt.Sprintf("%sd", format.Number(int(t.MedianGap)))
+}
+
+// Lines added minus lines removed, formatted like formatChange().
+func formatNetLines(t tally.FinalTally, width int) string {
+	return formatChange(t.LinesAdded-t.LinesRemoved, width)
+}
+
+// A number with a sign, colored green if positive or red if negative. Padded
+// on the left to the given width.
+func formatChange(change int, width int) string {
+	color := pretty.DefaultColor
+	num := format.Number(change)
+	if change > 0 {
+		color = pretty.Green
+		num = "+" + num
+	} else if change < 0 {
+		color = pretty.Red
+	}
+
+	return fmt.Sprintf("%s%*s%s", color, width, num, pretty.DefaultColor)
+}
@@ -0,0 +1,344 @@
+package subcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"runtime"
+	"sync"
+
+	"github.com/sinclairtarget/git-who/internal/cache"
+	"github.com/sinclairtarget/git-who/internal/concurrent"
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	"github.com/sinclairtarget/git-who/internal/pretty"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+// A repository we tally as part of a larger whole. Either one of several
+// repositories given on the command line, a checked-out submodule, or the
+// superproject containing the submodules.
+type tallyTarget struct {
+	path        string // Prefix for paths in this repository, may be empty
+	rootPath    string // Absolute path to the working tree
+	revs        []string
+	pathspecs   []string // Relative to rootPath
+	configFiles config.SupplementalFiles
+	gitlinks    []string // Paths of submodules we also tally, relative to rootPath
+}
+
+// Context that runs Git in the target's working tree.
+func (t tallyTarget) context(ctx context.Context) context.Context {
+	return cmd.WithDir(ctx, t.rootPath)
+}
+
+// Whether revisions other than the default were given for the target.
+func hasRevs(t tallyTarget) bool {
+	return len(t.revs) != 1 || t.revs[0] != "HEAD"
+}
+
+// Finds the targets to tally: the given repositories if there are any, or else
+// the repository in the working directory, along with its submodules if
+// recurseSubmodules is set. The context should come from repos.context().
+func findTargets(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	repos RepoSet,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+) ([]tallyTarget, error) {
+	if !repos.IsEmpty() {
+		return findRepoTargets(ctx, repos, ignoreRevsFiles, recurseSubmodules)
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurseSubmodules {
+		return findSubmoduleTargets(
+			ctx,
+			revs,
+			pathspecs,
+			gitRootPath,
+			configFiles,
+		)
+	}
+
+	target, err := workingDirTarget(revs, pathspecs, gitRootPath, configFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return []tallyTarget{target}, nil
+}
+
+type combinable[T any] interface {
+	Combine(other T) T
+}
+
+// author -> tally, for tallies that don't need paths
+type authorTallies map[string]tally.Tally
+
+func (a authorTallies) Combine(b authorTallies) authorTallies {
+	for key, t := range b {
+		if existing, ok := a[key]; ok {
+			t = existing.Combine(t)
+		}
+
+		a[key] = t
+	}
+
+	return a
+}
+
+// commit hash -> something we found out about the commit
+type byCommit[V any] map[string]V
+
+func (a byCommit[V]) Combine(b byCommit[V]) byCommit[V] {
+	maps.Copy(a, b)
+	return a
+}
+
+// Runs f for each target, several targets at a time, and combines the results
+// in the order of the targets.
+//
+// Targets tallied at the same time still share the same number of git log
+// workers between them (see the concurrent package).
+//
+// We only allow a progress bar when there is a single target, since several
+// progress bars would overwrite each other.
+func tallyEachTarget[T combinable[T]](
+	ctx context.Context,
+	targets []tallyTarget,
+	f func(context.Context, tallyTarget, bool) (T, error),
+) (_ T, err error) {
+	results := make([]T, len(targets))
+	errs := make([]error, len(targets))
+
+	allowProgressBar := len(targets) == 1 && pretty.AllowDynamic(os.Stdout)
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = f(target.context(ctx), target, allowProgressBar)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf(
+					"failed to tally commits in \"%s\": %w",
+					target.rootPath,
+					errs[i],
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var combined T
+	if err := errors.Join(errs...); err != nil {
+		return combined, err
+	}
+
+	combined = results[0]
+	for _, result := range results[1:] {
+		combined = combined.Combine(result)
+	}
+
+	return combined, nil
+}
+
+// Tallies commits in all the targets, returning one tally per author.
+func tallyTargets(
+	ctx context.Context,
+	targets []tallyTarget,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	opts tally.TallyOpts,
+) (map[string]tally.Tally, error) {
+	if opts.IsDiffMode() {
+		byPath, err := tallyTargetsByPath(ctx, targets, filters, diffOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return byPath.Reduce(), nil
+	}
+
+	return tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			_ bool,
+		) (_ authorTallies, err error) {
+			commits, finish := git.CommitsWithOpts(
+				ctx,
+				target.revs,
+				target.pathspecs,
+				filters,
+				false,
+				diffOpts,
+				target.configFiles,
+			)
+			defer func() { err = finish() }()
+
+			return tally.TallyCommits(commits, opts)
+		},
+	)
+}
+
+// Tallies commits by path in all the targets. Paths are prefixed with the path
+// of the target they belong to.
+func tallyTargetsByPath(
+	ctx context.Context,
+	targets []tallyTarget,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	opts tally.TallyOpts,
+) (tally.TalliesByPath, error) {
+	return tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			allowProgressBar bool,
+		) (_ tally.TalliesByPath, err error) {
+			var byPath tally.TalliesByPath
+			if runtime.GOMAXPROCS(0) > 1 {
+				byPath, err = concurrent.TallyCommitsByPath(
+					ctx,
+					target.revs,
+					target.pathspecs,
+					filters,
+					diffOpts,
+					target.configFiles,
+					opts,
+					cache.GetCache(target.rootPath, target.configFiles, diffOpts),
+					allowProgressBar,
+				)
+			} else {
+				byPath, err = func() (_ tally.TalliesByPath, err error) {
+					commits, finish := git.CommitsWithOpts(
+						ctx,
+						target.revs,
+						target.pathspecs,
+						filters,
+						true,
+						diffOpts,
+						target.configFiles,
+					)
+					defer func() { err = finish() }()
+
+					var renames tally.Renames
+					if opts.FollowRenames {
+						commits = tally.TeeRenames(commits, &renames)
+					}
+
+					byPath, err := tally.TallyCommitsByPath(commits, opts)
+					return byPath.FollowRenames(renames), err
+				}()
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			byPath = byPath.MoveToNoDiff(target.gitlinks)
+			return byPath.WithPrefix(target.path), nil
+		},
+	)
+}
+
+// Tallies commits in all the targets into daily time buckets.
+func tallyTargetsByDate(
+	ctx context.Context,
+	targets []tallyTarget,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	opts tally.TallyOpts,
+) (tally.TimeSeries, error) {
+	return tallyEachTarget(
+		ctx,
+		targets,
+		func(
+			ctx context.Context,
+			target tallyTarget,
+			allowProgressBar bool,
+		) (_ tally.TimeSeries, err error) {
+			populateDiffs := opts.IsDiffMode()
+			if populateDiffs && runtime.GOMAXPROCS(0) > 1 {
+				return concurrent.TallyCommitsByDate(
+					ctx,
+					target.revs,
+					target.pathspecs,
+					filters,
+					diffOpts,
+					target.configFiles,
+					opts,
+					cache.GetCache(target.rootPath, target.configFiles, diffOpts),
+					allowProgressBar,
+				)
+			}
+
+			commits, finish := git.CommitsWithOpts(
+				ctx,
+				target.revs,
+				target.pathspecs,
+				filters,
+				populateDiffs,
+				diffOpts,
+				target.configFiles,
+			)
+			defer func() { err = finish() }()
+
+			return tally.TallyCommitsByDate(commits, opts)
+		},
+	)
+}
+
+// Returns the paths in the working trees of all the targets, prefixed with the
+// path of the target they belong to.
+func targetsWorkingTreeFiles(
+	ctx context.Context,
+	targets []tallyTarget,
+) (map[string]bool, error) {
+	wtreeset := map[string]bool{}
+
+	for _, target := range targets {
+		paths, err := git.WorkingTreeFilesContext(
+			target.context(ctx),
+			target.pathspecs,
+			false,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for p := range paths {
+			wtreeset[path.Join(target.path, p)] = true
+		}
+	}
+
+	return wtreeset, nil
+}
@@ -27,6 +27,7 @@ type printTreeOpts struct {
 	mode       tally.TallyMode
 	maxDepth   int
 	showHidden bool
+	showPct    bool // Show top author's share of the total for each path
 	key        func(t tally.FinalTally) string
 }
 
@@ -44,15 +45,29 @@ type treeOutputLine struct {
 func Tree(
 	revs []string,
 	pathspecs []string,
+	repos RepoSet,
 	mode tally.TallyMode,
+	scoreExpr string,
 	depth int,
 	showEmail bool,
 	showHidden bool,
+	showPct bool,
 	countMerges bool,
+	followRenames bool,
+	recurseSubmodules bool,
+	identity tally.IdentityMode,
+	autoMerge bool,
+	splitSquashes bool,
+	dateMode tally.DateMode,
 	since string,
 	until string,
 	authors []string,
 	nauthors []string,
+	omitCherryPicks bool,
+	firstParent bool,
+	creditBranchAuthor bool,
+	ignoreRevsFiles []string,
+	diffOpts cmd.DiffOpts,
 ) (err error) {
 	defer func() {
 		if err != nil {
@@ -66,16 +81,34 @@ func Tree(
 		revs,
 		"pathspecs",
 		pathspecs,
+		"repos",
+		repos,
 		"mode",
 		mode,
+		"scoreExpr",
+		scoreExpr,
 		"depth",
 		depth,
 		"showEmail",
 		showEmail,
 		"showHidden",
 		showHidden,
+		"showPct",
+		showPct,
 		"countMerges",
 		countMerges,
+		"followRenames",
+		followRenames,
+		"recurseSubmodules",
+		recurseSubmodules,
+		"identity",
+		identity,
+		"autoMerge",
+		autoMerge,
+		"splitSquashes",
+		splitSquashes,
+		"dateMode",
+		dateMode,
 		"since",
 		since,
 		"until",
@@ -84,90 +117,125 @@ func Tree(
 		authors,
 		"nauthors",
 		nauthors,
+		"omitCherryPicks",
+		omitCherryPicks,
+		"firstParent",
+		firstParent,
+		"creditBranchAuthor",
+		creditBranchAuthor,
+		"ignoreRevsFiles",
+		ignoreRevsFiles,
+		"diffOpts",
+		diffOpts,
 	)
 
-	wtreeset, err := git.WorkingTreeFiles(pathspecs)
-	if err != nil {
-		return err
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	filters := cmd.LogFilters{
-		Since:    since,
-		Until:    until,
-		Authors:  authors,
-		Nauthors: nauthors,
+		Since:           since,
+		Until:           until,
+		Authors:         authors,
+		Nauthors:        nauthors,
+		OmitCherryPicks: omitCherryPicks,
+		FirstParent:     firstParent,
 	}
 
-	tallyOpts := tally.TallyOpts{Mode: mode, CountMerges: countMerges}
+	tallyOpts := tally.TallyOpts{
+		Mode:          mode,
+		CountMerges:   countMerges,
+		FollowRenames: followRenames,
+		Identity:      identity,
+		Date:          dateMode,
+		FirstParent:   firstParent,
+	}
 	if showEmail {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorEmail }
 	} else {
 		tallyOpts.Key = func(c git.Commit) string { return c.AuthorName }
 	}
 
-	gitRootPath, err := git.GetRoot()
-	if err != nil {
-		return err
+	if mode == tally.ScoreMode {
+		tallyOpts.Score, err = parseScore(ctx, scoreExpr)
+		if err != nil {
+			return err
+		}
 	}
 
-	configFiles, err := config.DetectSupplementalFiles(gitRootPath)
-	if err != nil {
-		return err
+	if creditBranchAuthor {
+		tallyOpts.BranchAuthors, err = branchAuthors(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			ignoreRevsFiles,
+			recurseSubmodules,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	var root *tally.TreeNode
-	if runtime.GOMAXPROCS(0) > 1 {
-		root, err = concurrent.TallyCommitsTree(
+	if splitSquashes {
+		tallyOpts.SquashCoAuthors, err = squashCoAuthors(
 			ctx,
 			revs,
 			pathspecs,
+			repos,
 			filters,
-			configFiles,
-			tallyOpts,
-			wtreeset,
-			gitRootPath,
-			cache.GetCache(gitRootPath, configFiles),
-			pretty.AllowDynamic(os.Stdout),
+			ignoreRevsFiles,
+			recurseSubmodules,
 		)
-
-		if err == tally.EmptyTreeErr {
-			logger().Debug("Tree was empty.")
-			return nil
+		if err != nil {
+			return err
 		}
+	}
 
+	if autoMerge {
+		tallyOpts.Merge, err = mergeIdentities(
+			ctx,
+			revs,
+			pathspecs,
+			repos,
+			filters,
+			ignoreRevsFiles,
+			recurseSubmodules,
+			identity,
+		)
 		if err != nil {
 			return err
 		}
-	} else {
-		root, err = func() (_ *tally.TreeNode, err error) {
-			commits, finish := git.CommitsWithOpts(
-				ctx,
-				revs,
-				pathspecs,
-				filters,
-				true,
-				configFiles,
-			)
-			defer func() { err = finish() }()
+	}
 
-			root, err := tally.TallyCommitsTree(
-				commits,
-				tallyOpts,
-				wtreeset,
-				gitRootPath,
-			)
-			return root, err
-		}()
+	var root *tally.TreeNode
+	if !repos.IsEmpty() {
+		root, err = tallyReposTree(
+			ctx,
+			repos,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			recurseSubmodules,
+			tallyOpts,
+		)
+	} else {
+		root, err = tallyRepoTree(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			ignoreRevsFiles,
+			recurseSubmodules,
+			tallyOpts,
+		)
+	}
 
-		if err == tally.EmptyTreeErr {
-			logger().Debug("Tree was empty.")
-			return nil
-		} else if err != nil {
-			return fmt.Errorf("failed to tally commits: %w", err)
-		}
+	if err == tally.EmptyTreeErr {
+		logger().Debug("Tree was empty.")
+		return nil
+	} else if err != nil {
+		return err
 	}
 
 	root = root.Rank(mode)
@@ -181,6 +249,7 @@ func Tree(
 		maxDepth:   maxDepth,
 		mode:       mode,
 		showHidden: showHidden,
+		showPct:    showPct,
 	}
 	if showEmail {
 		opts.key = func(t tally.FinalTally) string { return t.AuthorEmail }
@@ -193,6 +262,145 @@ func Tree(
 	return nil
 }
 
+// Tallies commits in the repository in the working directory into a tree.
+func tallyRepoTree(
+	ctx context.Context,
+	revs []string,
+	pathspecs []string,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+	tallyOpts tally.TallyOpts,
+) (*tally.TreeNode, error) {
+	wtreeset, err := git.WorkingTreeFiles(pathspecs, recurseSubmodules)
+	if err != nil {
+		return nil, err
+	}
+
+	gitRootPath, err := git.GetRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(
+		gitRootPath,
+		ignoreRevsFiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurseSubmodules {
+		targets, err := findSubmoduleTargets(
+			ctx,
+			revs,
+			pathspecs,
+			gitRootPath,
+			configFiles,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		byPath, err := tallyTargetsByPath(
+			ctx,
+			targets,
+			filters,
+			diffOpts,
+			tallyOpts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return tally.TallyCommitsTreeFromPaths(byPath, wtreeset, gitRootPath)
+	} else if runtime.GOMAXPROCS(0) > 1 {
+		return concurrent.TallyCommitsTree(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			diffOpts,
+			configFiles,
+			tallyOpts,
+			wtreeset,
+			gitRootPath,
+			cache.GetCache(gitRootPath, configFiles, diffOpts),
+			pretty.AllowDynamic(os.Stdout),
+		)
+	}
+
+	root, err := func() (_ *tally.TreeNode, err error) {
+		commits, finish := git.CommitsWithOpts(
+			ctx,
+			revs,
+			pathspecs,
+			filters,
+			true,
+			diffOpts,
+			configFiles,
+		)
+		defer func() {
+			// Don't clobber EmptyTreeErr
+			if finishErr := finish(); finishErr != nil {
+				err = finishErr
+			}
+		}()
+
+		root, err := tally.TallyCommitsTree(
+			commits,
+			tallyOpts,
+			wtreeset,
+			gitRootPath,
+		)
+		return root, err
+	}()
+
+	if err != nil && err != tally.EmptyTreeErr {
+		return nil, fmt.Errorf("failed to tally commits: %w", err)
+	}
+
+	return root, err
+}
+
+// Tallies commits in several repositories into a single tree with a top-level
+// directory for each repository.
+func tallyReposTree(
+	ctx context.Context,
+	repos RepoSet,
+	filters cmd.LogFilters,
+	diffOpts cmd.DiffOpts,
+	ignoreRevsFiles []string,
+	recurseSubmodules bool,
+	tallyOpts tally.TallyOpts,
+) (*tally.TreeNode, error) {
+	ctx = repos.context(ctx)
+
+	targets, err := findRepoTargets(
+		ctx,
+		repos,
+		ignoreRevsFiles,
+		recurseSubmodules,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wtreeset, err := targetsWorkingTreeFiles(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath, err := tallyTargetsByPath(ctx, targets, filters, diffOpts, tallyOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paths are already relative to the top of the tree
+	return tally.TallyCommitsTreeFromPaths(byPath, wtreeset, "")
+}
+
 // Recursively descend tree, turning tree nodes into output lines.
 func toLines(
 	node *tally.TreeNode,
@@ -255,6 +463,10 @@ func toLines(
 
 	line.tally = node.Tally
 	line.metric = fmtTallyMetric(node.Tally, opts)
+	if opts.showPct {
+		share := format.Percent(node.TopShare(opts.mode))
+		line.metric = fmt.Sprintf("%s %s", line.metric, share)
+	}
 	line.showLine = node.InWorkTree || opts.showHidden
 	line.dimTally = len(node.Children) > 0
 	line.dimPath = !node.InWorkTree
@@ -312,7 +524,11 @@ func fmtTallyMetric(t tally.FinalTally, opts printTreeOpts) string {
 		return fmt.Sprintf("(%s)", format.Number(t.Commits))
 	case tally.FilesMode:
 		return fmt.Sprintf("(%s)", format.Number(t.FileCount))
-	case tally.LinesMode:
+	case tally.NetLinesMode:
+		return fmt.Sprintf("(%s)", formatNetLines(t, 0))
+	case tally.ScoreMode:
+		return fmt.Sprintf("(score %s)", formatScore(t.Score))
+	case tally.LinesMode, tally.LinesAddedMode, tally.LinesRemovedMode:
 		return fmt.Sprintf(
 			"(%s%s%s / %s%s%s)",
 			pretty.Green,
@@ -0,0 +1,82 @@
+package tally
+
+import (
+	"slices"
+	"time"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// Returns the number of days since the Unix epoch of the calendar date of the
+// given time in its own location. Consecutive dates have consecutive day
+// numbers.
+//
+// Pass the commit's author-local time, like the punchcard uses, so that the
+// days an author was active don't depend on the time zone we run in.
+func dayNumber(t time.Time) int {
+	year, month, day := t.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return int(date.Unix() / secondsPerDay)
+}
+
+// Metrics describing how regularly an author was active.
+type activity struct {
+	days          int // Num distinct days with a commit
+	weeks         int // Num distinct ISO weeks with a commit
+	longestStreak int // Most consecutive days with a commit
+	// Median num days from one active day to the next. We measure gaps
+	// between days rather than commits so that several commits on one day
+	// don't make the gap zero. With an even number of gaps this is the mean of
+	// the middle two, so it can be fractional
+	medianGap float64
+}
+
+func computeActivity(dayset map[int]bool) activity {
+	if len(dayset) == 0 {
+		return activity{}
+	}
+
+	days := make([]int, 0, len(dayset))
+	weekset := map[[2]int]bool{}
+	for day := range dayset {
+		days = append(days, day)
+
+		year, week := time.Unix(int64(day)*secondsPerDay, 0).UTC().ISOWeek()
+		weekset[[2]int{year, week}] = true
+	}
+	slices.Sort(days)
+
+	longestStreak := 1
+	streak := 1
+	gaps := []int{}
+	for i := 1; i < len(days); i++ {
+		gap := days[i] - days[i-1]
+		gaps = append(gaps, gap)
+
+		if gap == 1 {
+			streak += 1
+			longestStreak = max(longestStreak, streak)
+		} else {
+			streak = 1
+		}
+	}
+
+	medianGap := 0.0
+	if len(gaps) > 0 {
+		slices.Sort(gaps)
+
+		mid := len(gaps) / 2
+		if len(gaps)%2 == 0 {
+			medianGap = float64(gaps[mid-1]+gaps[mid]) / 2
+		} else {
+			medianGap = float64(gaps[mid])
+		}
+	}
+
+	return activity{
+		days:          len(days),
+		weeks:         len(weekset),
+		longestStreak: longestStreak,
+		medianGap:     medianGap,
+	}
+}
@@ -33,8 +33,8 @@ func (b TimeBucket) Value(mode TallyMode) int {
 		return b.Tally.Commits
 	case FilesMode:
 		return b.Tally.FileCount
-	case LinesMode:
-		return b.Tally.LinesAdded + b.Tally.LinesRemoved
+	case LinesMode, LinesAddedMode, LinesRemovedMode, NetLinesMode:
+		return int(b.Tally.SortKey(mode))
 	default:
 		panic("unrecognized tally mode in switch")
 	}
@@ -46,8 +46,8 @@ func (b TimeBucket) TotalValue(mode TallyMode) int {
 		return b.TotalTally.Commits
 	case FilesMode:
 		return b.TotalTally.FileCount
-	case LinesMode:
-		return b.TotalTally.LinesAdded + b.TotalTally.LinesRemoved
+	case LinesMode, LinesAddedMode, LinesRemovedMode, NetLinesMode:
+		return int(b.TotalTally.SortKey(mode))
 	default:
 		panic("unrecognized tally mode in switch")
 	}
@@ -89,6 +89,11 @@ func (b TimeBucket) Rank(mode TallyMode) TimeBucket {
 	return b
 }
 
+// Returns the tally for each author in the bucket, ranked according to mode.
+func (b TimeBucket) Ranked(mode TallyMode) []FinalTally {
+	return Rank(b.tallies, mode)
+}
+
 type TimeSeries []TimeBucket
 
 func (a TimeSeries) Combine(b TimeSeries) TimeSeries {
@@ -211,7 +216,7 @@ func TallyCommitsByDate(
 	buckets := map[int64]TimeBucket{} // Map of (unix) time to bucket
 
 	// Tally
-	for commit := range commits {
+	for commit := range opts.credited(commits) {
 		bucketedCommitTime := resolution.apply(commit.Date)
 		if bucketedCommitTime.Before(minTime) {
 			minTime = bucketedCommitTime
@@ -286,18 +291,24 @@ func TallyCommitsTimeline(
 		return buckets, err
 	}
 
-	if len(buckets) == 0 {
-		return buckets, err
+	return TimeSeries(buckets).Timeline(end), nil
+}
+
+// Re-buckets the daily time series at a resolution suited to the duration
+// between the first bucket and the end time.
+//
+// If the end time is zero, the timeline ends at the last bucket.
+func (ts TimeSeries) Timeline(end time.Time) []TimeBucket {
+	if len(ts) == 0 {
+		return ts
 	}
 
 	if end.IsZero() {
-		end = buckets[len(buckets)-1].Time
+		end = ts[len(ts)-1].Time
 	}
 
-	resolution := CalcResolution(buckets[0].Time, end)
-	rebuckets := Rebucket(buckets, resolution, end)
-
-	return rebuckets, nil
+	resolution := CalcResolution(ts[0].Time, end)
+	return Rebucket(ts, resolution, end)
 }
 
 func Rebucket(
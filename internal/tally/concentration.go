@@ -0,0 +1,79 @@
+package tally
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Measures of how concentrated contributions are among a group of authors for
+// some tally mode. Shares are percentages.
+type Concentration struct {
+	Authors int     // Num authors contributing something
+	Total   int64   // Sum over all authors
+	Gini    float64 // 0 when all authors contribute equally, near 1 when one does
+	// Herfindahl index, the sum of squared shares as fractions. Ranges from
+	// 1 / Authors when all authors contribute equally to 1 for a single author
+	HHI       float64
+	Top1Share float64
+	Top5Share float64
+	// Fewest authors who together account for at least half of the total
+	BusFactor int
+}
+
+// Computes concentration measures for the given tallies, which should already
+// be ranked according to mode. Authors contributing nothing are left out.
+func MeasureConcentration(ranked []FinalTally, mode TallyMode) Concentration {
+	if !mode.HasShares() {
+		panic("cannot measure concentration in this tally mode")
+	}
+
+	values := []int64{}
+	var total int64
+	for _, t := range ranked {
+		value := t.SortKey(mode)
+		if value <= 0 {
+			continue
+		}
+
+		values = append(values, value)
+		total += value
+	}
+
+	if total == 0 {
+		return Concentration{}
+	}
+
+	// Largest first
+	slices.SortFunc(values, func(a, b int64) int {
+		return cmp.Compare(b, a)
+	})
+
+	c := Concentration{
+		Authors: len(values),
+		Total:   total,
+	}
+
+	var running int64
+	var weightedSum float64 // Sum of values weighted by ascending rank
+	n := len(values)
+	for i, value := range values {
+		share := float64(value) / float64(total)
+		c.HHI += share * share
+
+		running += value
+		if i == 0 {
+			c.Top1Share = percent(running, total)
+		}
+		if i < 5 {
+			c.Top5Share = percent(running, total)
+		}
+		if c.BusFactor == 0 && 2*running >= total {
+			c.BusFactor = i + 1
+		}
+
+		weightedSum += float64(n-i) * float64(value)
+	}
+
+	c.Gini = 2*weightedSum/(float64(n)*float64(total)) - float64(n+1)/float64(n)
+	return c
+}
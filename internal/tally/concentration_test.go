@@ -0,0 +1,64 @@
+package tally_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestMeasureConcentration(t *testing.T) {
+	ranked := []tally.FinalTally{
+		tally.FinalTally{AuthorName: "bob", Commits: 6},
+		tally.FinalTally{AuthorName: "jim", Commits: 2},
+		tally.FinalTally{AuthorName: "tim", Commits: 1},
+		tally.FinalTally{AuthorName: "kim", Commits: 1},
+		tally.FinalTally{AuthorName: "zed", Commits: 0},
+	}
+
+	c := tally.MeasureConcentration(ranked, tally.CommitMode)
+
+	expected := tally.Concentration{
+		Authors:   4,
+		Total:     10,
+		Gini:      0.4,
+		HHI:       0.42,
+		Top1Share: 60,
+		Top5Share: 100,
+		BusFactor: 1,
+	}
+	if diff := cmp.Diff(expected, c, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("concentration is wrong:\n%s", diff)
+	}
+}
+
+func TestMeasureConcentrationEqual(t *testing.T) {
+	ranked := []tally.FinalTally{}
+	for range 8 {
+		ranked = append(ranked, tally.FinalTally{LinesAdded: 5})
+	}
+
+	c := tally.MeasureConcentration(ranked, tally.LinesMode)
+
+	expected := tally.Concentration{
+		Authors:   8,
+		Total:     40,
+		Gini:      0,
+		HHI:       0.125,
+		Top1Share: 12.5,
+		Top5Share: 62.5,
+		BusFactor: 4,
+	}
+	if diff := cmp.Diff(expected, c, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("concentration is wrong:\n%s", diff)
+	}
+}
+
+func TestMeasureConcentrationEmpty(t *testing.T) {
+	c := tally.MeasureConcentration([]tally.FinalTally{}, tally.CommitMode)
+	if diff := cmp.Diff(tally.Concentration{}, c); diff != "" {
+		t.Errorf("concentration is wrong:\n%s", diff)
+	}
+}
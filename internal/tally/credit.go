@@ -0,0 +1,275 @@
+package tally
+
+import (
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+// Whose identity a commit is credited to.
+type IdentityMode int
+
+const (
+	AuthorIdentity IdentityMode = iota
+	CommitterIdentity
+	AuthorOrCommitterIdentity // Author and committer both get credit
+	ReviewerIdentity          // Credit goes to people named in trailers
+	ExtensionIdentity         // Credit goes to the file extensions edited
+)
+
+// Which of a commit's timestamps we tally by.
+type DateMode int
+
+const (
+	AuthorDate DateMode = iota
+	CommitterDate
+)
+
+func asCommitter(commit git.Commit) git.Commit {
+	commit.AuthorName = commit.CommitterName
+	commit.AuthorEmail = commit.CommitterEmail
+	return commit
+}
+
+func asTrailer(commit git.Commit, trailer git.Trailer) git.Commit {
+	commit.AuthorName = trailer.Name
+	commit.AuthorEmail = trailer.Email
+	return commit
+}
+
+// Returns a copy of the commit with every identity replaced with the identity
+// it is merged into.
+func (opts TallyOpts) merged(commit git.Commit) git.Commit {
+	commit.AuthorName, commit.AuthorEmail = opts.Merge(
+		commit.AuthorName,
+		commit.AuthorEmail,
+	)
+	commit.CommitterName, commit.CommitterEmail = opts.Merge(
+		commit.CommitterName,
+		commit.CommitterEmail,
+	)
+
+	if len(commit.Trailers) > 0 {
+		trailers := make([]git.Trailer, len(commit.Trailers))
+		for i, trailer := range commit.Trailers {
+			trailer.Name, trailer.Email = opts.Merge(trailer.Name, trailer.Email)
+			trailers[i] = trailer
+		}
+		commit.Trailers = trailers
+	}
+
+	return commit
+}
+
+// Returns a copy of the commit for each file extension it edits, with only the
+// diffs for files with that extension. The extension stands in for both the
+// author name and email.
+func byExtension(commit git.Commit) []git.Commit {
+	exts := []string{}
+	diffs := map[string][]git.FileDiff{}
+	for _, diff := range commit.FileDiffs {
+		ext := extension(diff.Path)
+		if _, ok := diffs[ext]; !ok {
+			exts = append(exts, ext)
+		}
+		diffs[ext] = append(diffs[ext], diff)
+	}
+
+	commits := []git.Commit{}
+	for _, ext := range exts {
+		c := commit
+		c.AuthorName = ext
+		c.AuthorEmail = ext
+		c.FileDiffs = diffs[ext]
+		commits = append(commits, c)
+	}
+
+	return commits
+}
+
+// Returns a copy of a squash commit for its author and each of its co-authors,
+// with the lines added and removed in each file split evenly between them.
+// Every copy still counts as editing every file.
+func splitSquash(commit git.Commit, coAuthors []git.Author) []git.Commit {
+	authors := []git.Author{{Name: commit.AuthorName, Email: commit.AuthorEmail}}
+	for _, coAuthor := range coAuthors {
+		isAuthor := len(coAuthor.Email) > 0 &&
+			strings.EqualFold(coAuthor.Email, commit.AuthorEmail)
+		if !isAuthor {
+			authors = append(authors, coAuthor)
+		}
+	}
+
+	// The first few authors get any lines left over
+	share := func(total int, i int) int {
+		n := total / len(authors)
+		if i < total%len(authors) {
+			n += 1
+		}
+		return n
+	}
+
+	commits := []git.Commit{}
+	for i, author := range authors {
+		c := commit
+		c.AuthorName = author.Name
+		c.AuthorEmail = author.Email
+
+		c.FileDiffs = make([]git.FileDiff, len(commit.FileDiffs))
+		for j, diff := range commit.FileDiffs {
+			diff.LinesAdded = share(diff.LinesAdded, i)
+			diff.LinesRemoved = share(diff.LinesRemoved, i)
+			c.FileDiffs[j] = diff
+		}
+
+		commits = append(commits, c)
+	}
+
+	return commits
+}
+
+// Returns an iterator over the commits with each squash commit listed in
+// opts.SquashCoAuthors split between its author and co-authors.
+func (opts TallyOpts) unsquashed(
+	commits iter.Seq[git.Commit],
+) iter.Seq[git.Commit] {
+	if opts.SquashCoAuthors == nil {
+		return commits
+	}
+
+	return func(yield func(git.Commit) bool) {
+		for commit := range commits {
+			coAuthors, ok := opts.SquashCoAuthors[commit.Hash]
+			if !ok {
+				if !yield(commit) {
+					return
+				}
+				continue
+			}
+
+			for _, c := range splitSquash(commit, coAuthors) {
+				if !yield(c) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Returns a copy of the commit for each person who reviewed, acked, tested, or
+// signed off on the commit. Authors signing off on their own commits are not
+// counted as reviewers.
+func (opts TallyOpts) reviewed(commit git.Commit) []git.Commit {
+	seen := map[string]bool{opts.Key(commit): true}
+	reviewed := []git.Commit{}
+
+	for _, trailer := range commit.Trailers {
+		c := asTrailer(commit, trailer)
+
+		key := opts.Key(c)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		reviewed = append(reviewed, c)
+	}
+
+	return reviewed
+}
+
+// Returns an iterator over commits as they should be credited according to
+// the tally options.
+//
+// The commits yielded have the identity being credited in their author fields
+// and the timestamp being tallied by in their date field. When tallying by
+// committer date, commits with a committer date in the future are skipped, like
+// those with an author date in the future are skipped when parsing. A commit credited to
+// more than one person is yielded once for each person. Squash commits are
+// first split between their co-authors if opts.SquashCoAuthors is set and
+// merge commits are credited to the authors of the branches they merged if
+// opts.BranchAuthors is set. Then duplicate identities are merged if
+// opts.Merge is set.
+func (opts TallyOpts) credited(
+	commits iter.Seq[git.Commit],
+) iter.Seq[git.Commit] {
+	commits = opts.unsquashed(commits)
+
+	if opts.Identity == AuthorIdentity &&
+		opts.Date == AuthorDate &&
+		opts.Merge == nil &&
+		!opts.FirstParent &&
+		opts.BranchAuthors == nil {
+		return commits
+	}
+
+	return func(yield func(git.Commit) bool) {
+		now := time.Now()
+
+		for commit := range commits {
+			if opts.Date == CommitterDate && commit.CommitterDate.After(now) {
+				logger().Debug(
+					"skipping commit with committer date in the future",
+					"commit",
+					commit.Name(),
+				)
+				continue
+			}
+
+			if opts.FirstParent {
+				commit.IsMerge = false
+			}
+
+			if author, ok := opts.BranchAuthors[commit.Hash]; ok {
+				commit.AuthorName = author.Name
+				commit.AuthorEmail = author.Email
+			}
+
+			if opts.Merge != nil {
+				commit = opts.merged(commit)
+			}
+
+			if opts.Date == CommitterDate {
+				commit.Date = commit.CommitterDate
+			}
+
+			switch opts.Identity {
+			case AuthorIdentity:
+				if !yield(commit) {
+					return
+				}
+			case CommitterIdentity:
+				if !yield(asCommitter(commit)) {
+					return
+				}
+			case AuthorOrCommitterIdentity:
+				if !yield(commit) {
+					return
+				}
+
+				committed := asCommitter(commit)
+				if opts.Key(committed) != opts.Key(commit) {
+					if !yield(committed) {
+						return
+					}
+				}
+			case ReviewerIdentity:
+				for _, reviewed := range opts.reviewed(commit) {
+					if !yield(reviewed) {
+						return
+					}
+				}
+			case ExtensionIdentity:
+				for _, edited := range byExtension(commit) {
+					if !yield(edited) {
+						return
+					}
+				}
+			default:
+				panic("unrecognized identity mode in switch")
+			}
+		}
+	}
+}
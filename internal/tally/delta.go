@@ -0,0 +1,103 @@
+package tally
+
+import (
+	"cmp"
+	"slices"
+)
+
+// How an author's contributions changed between two tallies for some tally
+// mode.
+type Delta struct {
+	AuthorName  string
+	AuthorEmail string
+	Before      int64 // Zero if author is missing from the first tally
+	After       int64 // Zero if author is missing from the second tally
+	InBefore    bool
+	InAfter     bool
+}
+
+func (d Delta) Change() int64 {
+	return d.After - d.Before
+}
+
+// Change as a percentage of the value in the first tally. The second return
+// value is false if there was nothing in the first tally to compare against.
+func (d Delta) RelativeChange() (float64, bool) {
+	if d.Before == 0 {
+		return 0, false
+	}
+
+	return percent(d.Change(), d.Before), true
+}
+
+// Author shows up only in the second tally.
+func (d Delta) IsNew() bool {
+	return d.InAfter && !d.InBefore
+}
+
+// Author shows up only in the first tally.
+func (d Delta) IsGone() bool {
+	return d.InBefore && !d.InAfter
+}
+
+// Whether it makes sense to subtract one tally from another in this mode. It
+// doesn't for modes that rank by time.
+func (mode TallyMode) HasDeltas() bool {
+	return mode != FirstModifiedMode && mode != LastModifiedMode
+}
+
+// Compares the tallies for each author between two sets of tallies keyed the
+// same way. Deltas are sorted from the largest gain to the largest loss.
+func Deltas(
+	before map[string]Tally,
+	after map[string]Tally,
+	mode TallyMode,
+) []Delta {
+	if !mode.HasDeltas() {
+		panic("cannot compute deltas in this tally mode")
+	}
+
+	deltas := map[string]Delta{}
+	for key, t := range before {
+		final := t.Final()
+		deltas[key] = Delta{
+			AuthorName:  final.AuthorName,
+			AuthorEmail: final.AuthorEmail,
+			Before:      final.SortKey(mode),
+			InBefore:    true,
+		}
+	}
+
+	for key, t := range after {
+		final := t.Final()
+
+		d, ok := deltas[key]
+		if !ok {
+			d.AuthorName = final.AuthorName
+			d.AuthorEmail = final.AuthorEmail
+		}
+
+		d.After = final.SortKey(mode)
+		d.InAfter = true
+		deltas[key] = d
+	}
+
+	sorted := []Delta{}
+	for _, d := range deltas {
+		sorted = append(sorted, d)
+	}
+
+	slices.SortFunc(sorted, func(a, b Delta) int {
+		if c := cmp.Compare(b.Change(), a.Change()); c != 0 {
+			return c
+		}
+
+		if c := cmp.Compare(b.After, a.After); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.AuthorName, b.AuthorName)
+	})
+
+	return sorted
+}
@@ -0,0 +1,94 @@
+package tally_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func tallyAuthors(t *testing.T, names ...string) map[string]tally.Tally {
+	commits := []git.Commit{}
+	for i, name := range names {
+		hash := string(rune('a' + i))
+		commits = append(commits, git.Commit{
+			Hash:        hash,
+			ShortHash:   hash,
+			AuthorName:  name,
+			AuthorEmail: name + "@mail.com",
+		})
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	return tallies
+}
+
+func TestDeltas(t *testing.T) {
+	before := tallyAuthors(t, "bob", "bob", "jim", "jim", "jim", "tim")
+	after := tallyAuthors(t, "bob", "bob", "bob", "bob", "jim", "kim")
+
+	deltas := tally.Deltas(before, after, tally.CommitMode)
+
+	expected := []tally.Delta{
+		tally.Delta{
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Before:      2,
+			After:       4,
+			InBefore:    true,
+			InAfter:     true,
+		},
+		tally.Delta{
+			AuthorName:  "kim",
+			AuthorEmail: "kim@mail.com",
+			After:       1,
+			InAfter:     true,
+		},
+		tally.Delta{
+			AuthorName:  "tim",
+			AuthorEmail: "tim@mail.com",
+			Before:      1,
+			InBefore:    true,
+		},
+		tally.Delta{
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Before:      3,
+			After:       1,
+			InBefore:    true,
+			InAfter:     true,
+		},
+	}
+	if diff := cmp.Diff(expected, deltas); diff != "" {
+		t.Errorf("deltas are wrong:\n%s", diff)
+	}
+
+	if !deltas[1].IsNew() || deltas[1].IsGone() {
+		t.Errorf("expected kim to be new")
+	}
+
+	if !deltas[2].IsGone() || deltas[2].IsNew() {
+		t.Errorf("expected tim to be gone")
+	}
+
+	rel, ok := deltas[0].RelativeChange()
+	if !ok || rel != 100 {
+		t.Errorf("expected relative change of 100%% but got %v", rel)
+	}
+
+	_, ok = deltas[1].RelativeChange()
+	if ok {
+		t.Errorf("expected no relative change for new author")
+	}
+}
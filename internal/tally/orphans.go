@@ -0,0 +1,62 @@
+package tally
+
+// A path where departed authors own the majority of the tallied metric.
+type Orphan struct {
+	Path      string // Directories end with "/"
+	Owned     int64  // Metric owned by departed authors
+	Total     int64  // Metric owned by all authors
+	Owner     FinalTally
+	NextOwner *FinalTally // Best remaining author; nil if there isn't one
+}
+
+func (o Orphan) Share() float64 {
+	return float64(o.Owned) / float64(o.Total)
+}
+
+/*
+* Orphans() walks a ranked tree and returns the working-tree paths where the
+* departed authors together own more than half of the metric, in path order.
+*
+* Owner is the departed author who owns the most. Only commit, lines, and files
+* modes make sense here.
+ */
+func (t *TreeNode) Orphans(
+	mode TallyMode,
+	departed func(t FinalTally) bool,
+) []Orphan {
+	orphans := []Orphan{}
+	for p, node := range t.Paths() {
+		if orphan, ok := node.orphan(p, mode, departed); ok {
+			orphans = append(orphans, orphan)
+		}
+	}
+
+	return orphans
+}
+
+func (t *TreeNode) orphan(
+	p string,
+	mode TallyMode,
+	departed func(t FinalTally) bool,
+) (Orphan, bool) {
+	orphan := Orphan{Path: p}
+	hasOwner := false
+
+	// Ranked best first, so the first of each kind is the biggest owner
+	for _, final := range Rank(t.tallies, mode) {
+		value := final.SortKey(mode)
+		orphan.Total += value
+
+		if departed(final) {
+			orphan.Owned += value
+			if !hasOwner {
+				orphan.Owner = final
+				hasOwner = true
+			}
+		} else if orphan.NextOwner == nil {
+			orphan.NextOwner = &final
+		}
+	}
+
+	return orphan, orphan.Total > 0 && 2*orphan.Owned > orphan.Total
+}
@@ -0,0 +1,155 @@
+package tally_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestOrphans(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "foo/bim.txt",
+					LinesAdded:   20,
+					LinesRemoved: 0,
+				},
+				git.FileDiff{
+					Path:         "foo/bar.txt",
+					LinesAdded:   2,
+					LinesRemoved: 0,
+				},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "foo/bar.txt",
+					LinesAdded:   5,
+					LinesRemoved: 1,
+				},
+				git.FileDiff{
+					Path:         "foo/bim.txt",
+					LinesAdded:   4,
+					LinesRemoved: 0,
+				},
+			},
+		},
+		git.Commit{
+			Hash:        "bac",
+			ShortHash:   "bac",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "gone.txt",
+					LinesAdded:   50,
+					LinesRemoved: 0,
+				},
+			},
+		},
+	}
+
+	worktreeset := map[string]bool{"foo/bim.txt": true, "foo/bar.txt": true}
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	root, err := tally.TallyCommitsTree(
+		slices.Values(commits),
+		opts,
+		worktreeset,
+		"",
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsTree() returned error: %v", err)
+	}
+
+	root = root.Rank(opts.Mode)
+
+	orphans := root.Orphans(opts.Mode, func(t tally.FinalTally) bool {
+		return t.AuthorEmail == "bob@mail.com"
+	})
+
+	paths := []string{}
+	for _, orphan := range orphans {
+		paths = append(paths, orphan.Path)
+	}
+
+	expected := []string{"foo/", "foo/bim.txt"}
+	if diff := cmp.Diff(expected, paths); diff != "" {
+		t.Fatalf("orphan paths are wrong:\n%s", diff)
+	}
+
+	bim := orphans[1]
+	if bim.Owned != 20 || bim.Total != 24 {
+		t.Errorf("expected 20 of 24 lines owned but got %d of %d", bim.Owned, bim.Total)
+	}
+
+	if bim.Owner.AuthorEmail != "bob@mail.com" {
+		t.Errorf("expected owner to be bob but got %s", bim.Owner.AuthorEmail)
+	}
+
+	if bim.NextOwner == nil || bim.NextOwner.AuthorEmail != "jim@mail.com" {
+		t.Errorf("expected next owner to be jim but got %v", bim.NextOwner)
+	}
+}
+
+func TestOrphansNoNextOwner(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "bim.txt",
+					LinesAdded:   1,
+					LinesRemoved: 0,
+				},
+			},
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	root, err := tally.TallyCommitsTree(
+		slices.Values(commits),
+		opts,
+		map[string]bool{"bim.txt": true},
+		"",
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsTree() returned error: %v", err)
+	}
+
+	orphans := root.Rank(opts.Mode).Orphans(
+		opts.Mode,
+		func(t tally.FinalTally) bool { return true },
+	)
+	if len(orphans) != 1 {
+		t.Fatalf("expected one orphan but got %d", len(orphans))
+	}
+
+	if orphans[0].NextOwner != nil {
+		t.Errorf("expected no next owner but got %v", orphans[0].NextOwner)
+	}
+}
@@ -0,0 +1,373 @@
+package tally
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/utils/timeutils"
+)
+
+// Returned when the author has no commits among those tallied.
+var NoCommitsErr = errors.New("no commits found for author")
+
+// Metrics for one author on a single path, directory, or file extension.
+type PathStat struct {
+	Path         string `json:"path"`
+	Commits      int    `json:"commits"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	Files        int    `json:"files"`
+}
+
+type MonthCount struct {
+	Month   string `json:"month"` // Formatted YYYY-MM
+	Commits int    `json:"commits"`
+}
+
+// Number of commits changing a number of lines in the range [Min, Max].
+// Max is -1 for the last bucket.
+type SizeBucket struct {
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+	Commits int `json:"commits"`
+}
+
+func (b SizeBucket) Label() string {
+	if b.Max < 0 {
+		return strconv.Itoa(b.Min) + "+"
+	} else if b.Min == b.Max {
+		return strconv.Itoa(b.Min)
+	}
+
+	return strconv.Itoa(b.Min) + "-" + strconv.Itoa(b.Max)
+}
+
+// Another author who edited the same files as the profiled author.
+type CoContributor struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	SharedFiles int    `json:"shared_files"`
+	Commits     int    `json:"commits"` // Their commits editing the shared files
+}
+
+// Everything we know about a single author's contributions.
+type Profile struct {
+	Name            string          `json:"name"`
+	Email           string          `json:"email"`
+	Commits         int             `json:"commits"`
+	LinesAdded      int             `json:"lines_added"`
+	LinesRemoved    int             `json:"lines_removed"`
+	FileCount       int             `json:"file_count"`
+	FirstCommitTime time.Time       `json:"first_commit_time"`
+	LastCommitTime  time.Time       `json:"last_commit_time"`
+	ActiveDays      int             `json:"active_days"`
+	CommitsByMonth  []MonthCount    `json:"commits_by_month"`
+	Dirs            []PathStat      `json:"dirs"`
+	Files           []PathStat      `json:"files"`
+	Extensions      []PathStat      `json:"extensions"`
+	CommitSizes     []SizeBucket    `json:"commit_sizes"`
+	MedianSize      int             `json:"median_commit_size"`
+	CoContributors  []CoContributor `json:"co_contributors"`
+}
+
+// Upper bounds of the commit size buckets, in lines changed.
+var sizeBounds = []int{0, 9, 49, 199, 999}
+
+// Returns stats ordered by the given metric, most first.
+func TopPaths(stats []PathStat, mode TallyMode, limit int) []PathStat {
+	sorted := slices.Clone(stats)
+	slices.SortStableFunc(sorted, func(a, b PathStat) int {
+		return cmp.Or(
+			cmp.Compare(pathStatValue(b, mode), pathStatValue(a, mode)),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+
+	if limit > 0 && limit < len(sorted) {
+		sorted = sorted[:limit]
+	}
+
+	return sorted
+}
+
+func pathStatValue(s PathStat, mode TallyMode) int {
+	switch mode {
+	case CommitMode:
+		return s.Commits
+	case LinesMode:
+		return s.LinesAdded + s.LinesRemoved
+	case FilesMode:
+		return s.Files
+	default:
+		panic("unrecognized tally mode in switch")
+	}
+}
+
+// Running totals for a path or group of paths.
+type pathStatTally struct {
+	commitset map[string]bool
+	added     int
+	removed   int
+	fileset   map[string]bool
+}
+
+func (t *pathStatTally) add(commit git.Commit, diff git.FileDiff) {
+	if t.commitset == nil {
+		t.commitset = map[string]bool{}
+		t.fileset = map[string]bool{}
+	}
+
+	t.commitset[commit.ShortHash] = true
+	t.fileset[diff.Path] = true
+	if !commit.IsMerge {
+		t.added += diff.LinesAdded
+		t.removed += diff.LinesRemoved
+	}
+}
+
+func (t pathStatTally) stat(p string) PathStat {
+	return PathStat{
+		Path:         p,
+		Commits:      len(t.commitset),
+		LinesAdded:   t.added,
+		LinesRemoved: t.removed,
+		Files:        len(t.fileset),
+	}
+}
+
+func stats(tallies map[string]*pathStatTally) []PathStat {
+	s := []PathStat{}
+	for p, t := range tallies {
+		s = append(s, t.stat(p))
+	}
+
+	return TopPaths(s, CommitMode, 0)
+}
+
+// Groups files by extension, or by name for files without one.
+func extension(p string) string {
+	ext := path.Ext(p)
+	if len(ext) == 0 || ext == path.Base(p) {
+		return path.Base(p)
+	}
+
+	return strings.ToLower(ext)
+}
+
+// Tallies everything about the commits by one author. The author is the one
+// whose key, according to opts.Key, is the given key. Like the other tallies,
+// keys differing only in case belong to different authors.
+//
+// The commits by other authors are needed to find co-contributors.
+func TallyProfile(
+	commits iter.Seq[git.Commit],
+	opts TallyOpts,
+	author string,
+) (Profile, error) {
+	var profile Profile
+
+	allCommits := map[string]bool{}
+	var total pathStatTally
+	days := map[int]bool{}
+	months := map[string]int{}
+	sizes := []int{}
+	dirs := map[string]*pathStatTally{}
+	files := map[string]*pathStatTally{}
+	exts := map[string]*pathStatTally{}
+
+	// path -> author key -> commits
+	pathAuthors := map[string]map[string][]string{}
+	identities := map[string][2]string{} // author key -> name, email
+
+	for commit := range opts.credited(commits) {
+		if commit.IsMerge && !opts.CountMerges {
+			continue
+		}
+
+		key := opts.Key(commit)
+		if _, ok := identities[key]; !ok {
+			identities[key] = [2]string{commit.AuthorName, commit.AuthorEmail}
+		}
+
+		for _, diff := range commit.FileDiffs {
+			authors, ok := pathAuthors[diff.Path]
+			if !ok {
+				authors = map[string][]string{}
+				pathAuthors[diff.Path] = authors
+			}
+			authors[key] = append(authors[key], commit.ShortHash)
+		}
+
+		if key != author {
+			continue
+		}
+
+		// Dates in the author's time zone, like table -days
+		local := commit.AuthorLocalDate()
+
+		if len(allCommits) == 0 {
+			profile.Name = commit.AuthorName
+			profile.Email = commit.AuthorEmail
+			profile.FirstCommitTime = local
+		}
+		allCommits[commit.ShortHash] = true
+
+		profile.FirstCommitTime = timeutils.Min(profile.FirstCommitTime, local)
+		profile.LastCommitTime = timeutils.Max(profile.LastCommitTime, local)
+
+		days[dayNumber(local)] = true
+		months[local.Format("2006-01")] += 1
+
+		size := 0
+		for _, diff := range commit.FileDiffs {
+			for _, byPath := range []struct {
+				tallies map[string]*pathStatTally
+				key     string
+			}{
+				{files, diff.Path},
+				{dirs, path.Dir(diff.Path)},
+				{exts, extension(diff.Path)},
+			} {
+				t, ok := byPath.tallies[byPath.key]
+				if !ok {
+					t = &pathStatTally{}
+					byPath.tallies[byPath.key] = t
+				}
+				t.add(commit, diff)
+			}
+
+			total.add(commit, diff)
+			size += diff.LinesAdded + diff.LinesRemoved
+		}
+
+		if !commit.IsMerge {
+			sizes = append(sizes, size)
+		}
+	}
+
+	if len(allCommits) == 0 {
+		return profile, NoCommitsErr
+	}
+
+	profile.Commits = len(allCommits)
+	profile.LinesAdded = total.added
+	profile.LinesRemoved = total.removed
+	profile.FileCount = len(total.fileset)
+	profile.ActiveDays = len(days)
+	profile.CommitsByMonth = monthCounts(months)
+	profile.Dirs = stats(dirs)
+	profile.Files = stats(files)
+	profile.Extensions = stats(exts)
+	profile.CommitSizes, profile.MedianSize = sizeDistribution(sizes)
+	profile.CoContributors = coContributors(
+		author,
+		files,
+		pathAuthors,
+		identities,
+	)
+
+	return profile, nil
+}
+
+// Returns commit counts for every month between the first and last months with
+// a commit, inclusive. Months are formatted YYYY-MM, so they sort by date.
+func monthCounts(months map[string]int) []MonthCount {
+	counts := []MonthCount{}
+
+	names := slices.Sorted(maps.Keys(months))
+	if len(names) == 0 {
+		return counts
+	}
+
+	month, _ := time.Parse("2006-01", names[0])
+	last, _ := time.Parse("2006-01", names[len(names)-1])
+	for !month.After(last) {
+		name := month.Format("2006-01")
+		counts = append(counts, MonthCount{name, months[name]})
+		month = month.AddDate(0, 1, 0)
+	}
+
+	return counts
+}
+
+func sizeDistribution(sizes []int) ([]SizeBucket, int) {
+	buckets := []SizeBucket{}
+	lower := 0
+	for _, upper := range sizeBounds {
+		buckets = append(buckets, SizeBucket{Min: lower, Max: upper})
+		lower = upper + 1
+	}
+	buckets = append(buckets, SizeBucket{Min: lower, Max: -1})
+
+	for _, size := range sizes {
+		i := slices.IndexFunc(buckets, func(b SizeBucket) bool {
+			return b.Max < 0 || size <= b.Max
+		})
+		buckets[i].Commits += 1
+	}
+
+	median := 0
+	if len(sizes) > 0 {
+		sorted := slices.Sorted(slices.Values(sizes))
+		median = sorted[len(sorted)/2]
+	}
+
+	return buckets, median
+}
+
+// Returns the other authors who edited the author's files, ordered by number
+// of files shared.
+func coContributors(
+	author string,
+	files map[string]*pathStatTally,
+	pathAuthors map[string]map[string][]string,
+	identities map[string][2]string,
+) []CoContributor {
+	byKey := map[string]*CoContributor{}
+	commitsets := map[string]map[string]bool{}
+	for p := range files {
+		for key, commits := range pathAuthors[p] {
+			if key == author {
+				continue
+			}
+
+			c, ok := byKey[key]
+			if !ok {
+				ident := identities[key]
+				c = &CoContributor{Name: ident[0], Email: ident[1]}
+				byKey[key] = c
+				commitsets[key] = map[string]bool{}
+			}
+
+			c.SharedFiles += 1
+			for _, hash := range commits {
+				commitsets[key][hash] = true
+			}
+		}
+	}
+
+	result := []CoContributor{}
+	for key, c := range byKey {
+		c.Commits = len(commitsets[key])
+		result = append(result, *c)
+	}
+
+	slices.SortFunc(result, func(a, b CoContributor) int {
+		return cmp.Or(
+			cmp.Compare(b.SharedFiles, a.SharedFiles),
+			cmp.Compare(b.Commits, a.Commits),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email),
+		)
+	})
+
+	return result
+}
@@ -0,0 +1,205 @@
+package tally_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestTallyProfile(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "src/a.go", LinesAdded: 4, LinesRemoved: 1},
+				{Path: "src/b.go", LinesAdded: 100, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "src/a.go", LinesAdded: 2, LinesRemoved: 2},
+				{Path: "README.md", LinesAdded: 9, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			ShortHash:   "bac",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "README.md", LinesAdded: 3, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			ShortHash:   "bad",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "src/a.go", LinesAdded: 0, LinesRemoved: 5},
+			},
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorName },
+	}
+
+	profile, err := tally.TallyProfile(slices.Values(commits), opts, "bob")
+	if err != nil {
+		t.Fatalf("TallyProfile() returned error: %v", err)
+	}
+
+	expected := tally.Profile{
+		Name:            "bob",
+		Email:           "bob@mail.com",
+		Commits:         3,
+		LinesAdded:      107,
+		LinesRemoved:    6,
+		FileCount:       3,
+		FirstCommitTime: commits[0].Date,
+		LastCommitTime:  commits[3].Date,
+		ActiveDays:      2,
+		CommitsByMonth: []tally.MonthCount{
+			{Month: "2024-01", Commits: 1},
+			{Month: "2024-02", Commits: 0},
+			{Month: "2024-03", Commits: 2},
+		},
+		Dirs: []tally.PathStat{
+			{Path: "src", Commits: 2, LinesAdded: 104, LinesRemoved: 6, Files: 2},
+			{Path: ".", Commits: 1, LinesAdded: 3, Files: 1},
+		},
+		Files: []tally.PathStat{
+			{Path: "src/a.go", Commits: 2, LinesAdded: 4, LinesRemoved: 6, Files: 1},
+			{Path: "README.md", Commits: 1, LinesAdded: 3, Files: 1},
+			{Path: "src/b.go", Commits: 1, LinesAdded: 100, Files: 1},
+		},
+		Extensions: []tally.PathStat{
+			{Path: ".go", Commits: 2, LinesAdded: 104, LinesRemoved: 6, Files: 2},
+			{Path: ".md", Commits: 1, LinesAdded: 3, Files: 1},
+		},
+		CommitSizes: []tally.SizeBucket{
+			{Min: 0, Max: 0},
+			{Min: 1, Max: 9, Commits: 2},
+			{Min: 10, Max: 49},
+			{Min: 50, Max: 199, Commits: 1},
+			{Min: 200, Max: 999},
+			{Min: 1000, Max: -1},
+		},
+		MedianSize: 5,
+		CoContributors: []tally.CoContributor{
+			{Name: "jim", Email: "jim@mail.com", SharedFiles: 2, Commits: 1},
+		},
+	}
+
+	if diff := cmp.Diff(expected, profile); diff != "" {
+		t.Errorf("profile is wrong:\n%s", diff)
+	}
+
+	top := tally.TopPaths(profile.Files, tally.LinesMode, 1)
+	if len(top) != 1 || top[0].Path != "src/b.go" {
+		t.Errorf("expected src/b.go to have the most lines, got %v", top)
+	}
+}
+
+// Names differing in case are different authors, as they are in table
+func TestTallyProfileMatchesCase(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC),
+		},
+		git.Commit{
+			ShortHash:   "bab",
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@work.com",
+			Date:        time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorName },
+	}
+
+	profile, err := tally.TallyProfile(slices.Values(commits), opts, "bob")
+	if err != nil {
+		t.Fatalf("TallyProfile() returned error: %v", err)
+	}
+
+	if profile.Commits != 1 {
+		t.Errorf("expected 1 commit but got %d", profile.Commits)
+	}
+
+	_, err = tally.TallyProfile(slices.Values(commits), opts, "BOB")
+	if !errors.Is(err, tally.NoCommitsErr) {
+		t.Errorf("expected NoCommitsErr, got %v", err)
+	}
+}
+
+func TestTallyProfileNoCommits(t *testing.T) {
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorName },
+	}
+
+	_, err := tally.TallyProfile(slices.Values([]git.Commit{}), opts, "bob")
+	if !errors.Is(err, tally.NoCommitsErr) {
+		t.Errorf("expected NoCommitsErr, got %v", err)
+	}
+}
+
+// Days and months are those of the author's time zone, not the one we run in
+func TestTallyProfileAuthorTimeZone(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			ShortHash:    "baa",
+			AuthorName:   "bob",
+			AuthorEmail:  "bob@mail.com",
+			Date:         time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC),
+			AuthorOffset: 2 * 60 * 60, // 1am on February 1st for bob
+		},
+		git.Commit{
+			ShortHash:    "bab",
+			AuthorName:   "bob",
+			AuthorEmail:  "bob@mail.com",
+			Date:         time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+			AuthorOffset: 2 * 60 * 60,
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorName },
+	}
+
+	profile, err := tally.TallyProfile(slices.Values(commits), opts, "bob")
+	if err != nil {
+		t.Fatalf("TallyProfile() returned error: %v", err)
+	}
+
+	if profile.ActiveDays != 1 {
+		t.Errorf("expected 1 active day but got %d", profile.ActiveDays)
+	}
+
+	expectedMonths := []tally.MonthCount{{Month: "2024-02", Commits: 2}}
+	if diff := cmp.Diff(expectedMonths, profile.CommitsByMonth); diff != "" {
+		t.Errorf("commits by month are wrong:\n%s", diff)
+	}
+}
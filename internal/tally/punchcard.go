@@ -0,0 +1,51 @@
+package tally
+
+import (
+	"iter"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+// Number of commits by day of the week and hour of the day, indexed by
+// time.Weekday (Sunday first) and then hour.
+type Punchcard [7][24]int
+
+func (p Punchcard) Total() int {
+	total := 0
+	for _, hours := range p {
+		for _, n := range hours {
+			total += n
+		}
+	}
+
+	return total
+}
+
+// Most commits in any one hour of the week.
+func (p Punchcard) Max() int {
+	maxCommits := 0
+	for _, hours := range p {
+		for _, n := range hours {
+			maxCommits = max(maxCommits, n)
+		}
+	}
+
+	return maxCommits
+}
+
+// Tallies commits by the weekday and hour they were authored, in the author's
+// own time zone.
+func TallyPunchcard(commits iter.Seq[git.Commit], opts TallyOpts) Punchcard {
+	var punchcard Punchcard
+
+	for commit := range opts.credited(commits) {
+		if commit.IsMerge && !opts.CountMerges {
+			continue
+		}
+
+		local := commit.AuthorLocalDate()
+		punchcard[local.Weekday()][local.Hour()] += 1
+	}
+
+	return punchcard
+}
@@ -0,0 +1,74 @@
+package tally_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestTallyPunchcard(t *testing.T) {
+	// Friday 2024-12-27 13:01:44 UTC
+	date := time.Unix(1735304504, 0)
+
+	commits := []git.Commit{
+		git.Commit{
+			ShortHash:    "baa",
+			Date:         date,
+			AuthorOffset: 0,
+		},
+		git.Commit{
+			ShortHash:    "bab",
+			Date:         date,
+			AuthorOffset: 12 * 60 * 60, // Saturday 01:01 local
+		},
+		git.Commit{
+			ShortHash:    "bac",
+			Date:         date,
+			AuthorOffset: -5 * 60 * 60, // Friday 08:01 local
+		},
+		git.Commit{
+			ShortHash:    "bad",
+			Date:         date,
+			AuthorOffset: -5 * 60 * 60,
+		},
+		git.Commit{
+			ShortHash: "bae",
+			Date:      date,
+			IsMerge:   true,
+		},
+	}
+
+	punchcard := tally.TallyPunchcard(slices.Values(commits), tally.TallyOpts{})
+
+	expected := []struct {
+		weekday time.Weekday
+		hour    int
+		commits int
+	}{
+		{time.Friday, 13, 1},
+		{time.Saturday, 1, 1},
+		{time.Friday, 8, 2},
+	}
+	for _, e := range expected {
+		if punchcard[e.weekday][e.hour] != e.commits {
+			t.Errorf(
+				"expected %d commits on %v at %d but got %d",
+				e.commits,
+				e.weekday,
+				e.hour,
+				punchcard[e.weekday][e.hour],
+			)
+		}
+	}
+
+	if punchcard.Total() != 4 {
+		t.Errorf("expected 4 commits in total but got %d", punchcard.Total())
+	}
+
+	if punchcard.Max() != 2 {
+		t.Errorf("expected max of 2 but got %d", punchcard.Max())
+	}
+}
@@ -0,0 +1,136 @@
+package tally
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+)
+
+// A file rename seen while walking the commit log.
+type Rename struct {
+	From string
+	To   string
+	Hash string // Full hash of the commit that made the rename
+}
+
+// All the renames seen while walking (some part of) the commit log.
+//
+// Renames are replayed in the order they were seen, which is the log's
+// topological order when walking the log from start to finish. Renames
+// gathered from chunks of the log walked separately should be put back in log
+// order with InLogOrder() first.
+type Renames []Rename
+
+func (a Renames) Combine(b Renames) Renames {
+	return append(a, b...)
+}
+
+// Returns all commits in the input iterator while recording any renames they
+// contain.
+func TeeRenames(
+	commits iter.Seq[git.Commit],
+	renames *Renames,
+) iter.Seq[git.Commit] {
+	return func(yield func(git.Commit) bool) {
+		for commit := range commits {
+			for _, diff := range commit.FileDiffs {
+				if diff.IsRename() {
+					*renames = append(*renames, Rename{
+						From: diff.OldPath,
+						To:   diff.Path,
+						Hash: commit.Hash,
+					})
+				}
+			}
+
+			if !yield(commit) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the renames sorted by the position of the commit that made them in
+// the given list of revisions, oldest first. Renames made by commits not in the
+// list come last.
+func (r Renames) InLogOrder(revs []string) Renames {
+	position := map[string]int{}
+	for i, rev := range revs {
+		position[rev] = i
+	}
+
+	return slices.SortedStableFunc(
+		slices.Values(r),
+		func(a, b Rename) int {
+			aPos, ok := position[a.Hash]
+			if !ok {
+				aPos = len(revs)
+			}
+
+			bPos, ok := position[b.Hash]
+			if !ok {
+				bPos = len(revs)
+			}
+
+			return aPos - bPos
+		},
+	)
+}
+
+// Returns a map from every path that was renamed to its most recent name.
+func (r Renames) latestPaths() map[string]string {
+	// Map of current path to all the paths it was previously known by
+	earlier := map[string][]string{}
+	for _, rename := range r {
+		moved := append(earlier[rename.From], rename.From)
+		delete(earlier, rename.From)
+		earlier[rename.To] = append(earlier[rename.To], moved...)
+	}
+
+	latest := map[string]string{}
+	for current, paths := range earlier {
+		for _, p := range paths {
+			if p != current {
+				latest[p] = current
+			}
+		}
+	}
+
+	return latest
+}
+
+// Returns by-path tallies where the tallies for any path that was later
+// renamed are credited to the path's most recent name instead.
+//
+// If a new file is created at a path after the file previously at that path
+// was renamed, contributions to the new file will also be credited to the
+// renamed file. We can't tell the two apart once tallied by path.
+func (byPath TalliesByPath) FollowRenames(renames Renames) TalliesByPath {
+	latest := renames.latestPaths()
+	if len(latest) == 0 {
+		return byPath
+	}
+
+	followed := TalliesByPath{}
+	for key, pathTallies := range byPath {
+		followedPathTallies := map[string]Tally{}
+
+		for p, tally := range pathTallies {
+			if latestPath, ok := latest[p]; ok {
+				p = latestPath
+			}
+
+			existing, ok := followedPathTallies[p]
+			if ok {
+				tally = existing.combineSamePath(tally)
+			}
+
+			followedPathTallies[p] = tally
+		}
+
+		followed[key] = followedPathTallies
+	}
+
+	return followed
+}
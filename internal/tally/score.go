@@ -0,0 +1,51 @@
+package tally
+
+import (
+	"github.com/sinclairtarget/git-who/internal/score"
+)
+
+type scoreVariable struct {
+	name  string
+	value func(t FinalTally) int
+}
+
+// Metrics that can be used in a score expression.
+var scoreVariables = []scoreVariable{
+	{"commits", func(t FinalTally) int { return t.Commits }},
+	{"lines", func(t FinalTally) int { return t.LinesAdded + t.LinesRemoved }},
+	{"added", func(t FinalTally) int { return t.LinesAdded }},
+	{"removed", func(t FinalTally) int { return t.LinesRemoved }},
+	{"net", func(t FinalTally) int { return t.LinesAdded - t.LinesRemoved }},
+	{"files", func(t FinalTally) int { return t.FileCount }},
+	{"days", func(t FinalTally) int { return t.ActiveDays }},
+	{"weeks", func(t FinalTally) int { return t.ActiveWeeks }},
+	{"streak", func(t FinalTally) int { return t.LongestStreak }},
+}
+
+// Names of the metrics that can be used in a score expression.
+func ScoreVariables() []string {
+	names := []string{}
+	for _, v := range scoreVariables {
+		names = append(names, v.name)
+	}
+
+	return names
+}
+
+// Parses a score expression like "commits*3 + files + lines/100", returning a
+// function that computes the score for a tally. Use as TallyOpts.Score.
+func ParseScore(s string) (func(t FinalTally) float64, error) {
+	expr, err := score.Parse(s, ScoreVariables())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(t FinalTally) float64 {
+		values := make([]float64, len(scoreVariables))
+		for i, v := range scoreVariables {
+			values[i] = float64(v.value(t))
+		}
+
+		return expr.Eval(values)
+	}, nil
+}
@@ -0,0 +1,67 @@
+package tally
+
+// An author's share of the total for some tally mode, as a percentage.
+type Share struct {
+	Percent    float64
+	Cumulative float64 // Combined share of this author and those ranked above
+}
+
+// Whether it makes sense to talk about an author's share of the total in this
+// mode. It doesn't for modes that rank by time, for net lines, which can be
+// negative, or for custom scores, which can also be fractional.
+func (mode TallyMode) HasShares() bool {
+	return mode != FirstModifiedMode &&
+		mode != LastModifiedMode &&
+		mode != NetLinesMode &&
+		mode != ScoreMode
+}
+
+func percent(value int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return 100 * float64(value) / float64(total)
+}
+
+// Returns the share of the total held by each of the given tallies, which
+// should already be ranked according to mode.
+func Shares(ranked []FinalTally, mode TallyMode) []Share {
+	if !mode.HasShares() {
+		panic("cannot compute shares in this tally mode")
+	}
+
+	var total int64
+	for _, t := range ranked {
+		total += t.SortKey(mode)
+	}
+
+	shares := make([]Share, len(ranked))
+	var running int64
+	for i, t := range ranked {
+		value := t.SortKey(mode)
+		running += value
+
+		shares[i] = Share{
+			Percent:    percent(value, total),
+			Cumulative: percent(running, total),
+		}
+	}
+
+	return shares
+}
+
+// Returns the share of the total for this node held by its top author. Rank()
+// must have been called on the node first.
+func (t *TreeNode) TopShare(mode TallyMode) float64 {
+	if !mode.HasShares() {
+		panic("cannot compute shares in this tally mode")
+	}
+
+	var total int64
+	for _, tally := range t.tallies {
+		total += tally.Final().SortKey(mode)
+	}
+
+	return percent(t.Tally.SortKey(mode), total)
+}
@@ -0,0 +1,94 @@
+package tally_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestShares(t *testing.T) {
+	ranked := []tally.FinalTally{
+		tally.FinalTally{AuthorName: "bob", Commits: 5},
+		tally.FinalTally{AuthorName: "jim", Commits: 3},
+		tally.FinalTally{AuthorName: "tim", Commits: 2},
+	}
+
+	shares := tally.Shares(ranked, tally.CommitMode)
+
+	expected := []tally.Share{
+		tally.Share{Percent: 50, Cumulative: 50},
+		tally.Share{Percent: 30, Cumulative: 80},
+		tally.Share{Percent: 20, Cumulative: 100},
+	}
+	if diff := cmp.Diff(expected, shares); diff != "" {
+		t.Errorf("shares are wrong:\n%s", diff)
+	}
+}
+
+func TestSharesNoTotal(t *testing.T) {
+	ranked := []tally.FinalTally{
+		tally.FinalTally{AuthorName: "bob"},
+	}
+
+	shares := tally.Shares(ranked, tally.LinesMode)
+
+	expected := []tally.Share{tally.Share{}}
+	if diff := cmp.Diff(expected, shares); diff != "" {
+		t.Errorf("shares are wrong:\n%s", diff)
+	}
+}
+
+func TestTopShare(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "foo/bim.txt", LinesAdded: 6},
+				git.FileDiff{Path: "foo/bar.txt", LinesAdded: 2},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "foo/bim.txt", LinesAdded: 2},
+			},
+		},
+	}
+
+	worktreeset := map[string]bool{"foo/bim.txt": true, "foo/bar.txt": true}
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	root, err := tally.TallyCommitsTree(
+		slices.Values(commits),
+		opts,
+		worktreeset,
+		"",
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsTree() returned error: %v", err)
+	}
+
+	root = root.Rank(opts.Mode)
+
+	if share := root.TopShare(opts.Mode); share != 80 {
+		t.Errorf("expected top share of 80%% for root but got %v", share)
+	}
+
+	bimNode := root.Children["foo"].Children["bim.txt"]
+	if share := bimNode.TopShare(opts.Mode); share != 75 {
+		t.Errorf("expected top share of 75%% for bim.txt but got %v", share)
+	}
+}
@@ -0,0 +1,49 @@
+package tally
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// A path whose top contributor has not committed recently.
+type StalePath struct {
+	Path       string // Directories end with "/"
+	Owner      FinalTally
+	LastActive time.Time // Owner's last commit anywhere in the repo
+}
+
+/*
+* Stale() walks a ranked tree and returns the working-tree paths whose top
+* contributor last committed before the cutoff, least recently active first.
+*
+* lastActive returns when an author last committed anywhere in the repo, which
+* may be later than their last commit under the tree.
+ */
+func (t *TreeNode) Stale(
+	cutoff time.Time,
+	lastActive func(t FinalTally) time.Time,
+) []StalePath {
+	stale := []StalePath{}
+	for p, node := range t.Paths() {
+		last := lastActive(node.Tally)
+		if !last.Before(cutoff) {
+			continue
+		}
+
+		stale = append(stale, StalePath{
+			Path:       p,
+			Owner:      node.Tally,
+			LastActive: last,
+		})
+	}
+
+	slices.SortStableFunc(stale, func(a, b StalePath) int {
+		return cmp.Or(
+			a.LastActive.Compare(b.LastActive),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+
+	return stale
+}
@@ -0,0 +1,98 @@
+package tally_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/tally"
+)
+
+func TestStale(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "foo/bim.txt", LinesAdded: 4},
+				git.FileDiff{Path: "bar.txt", LinesAdded: 2},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bar.txt", LinesAdded: 3},
+			},
+		},
+		git.Commit{
+			Hash:        "bac",
+			ShortHash:   "bac",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bar.txt", LinesAdded: 1},
+				git.FileDiff{Path: "qux.txt", LinesAdded: 1},
+			},
+		},
+	}
+
+	worktreeset := map[string]bool{
+		"foo/bim.txt": true,
+		"bar.txt":     true,
+		"qux.txt":     true,
+	}
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	root, err := tally.TallyCommitsTree(
+		slices.Values(commits),
+		opts,
+		worktreeset,
+		"",
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsTree() returned error: %v", err)
+	}
+
+	// Bob committed elsewhere in the repo later than he did under this tree
+	lastActive := map[string]time.Time{
+		"bob@mail.com": time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		"jim@mail.com": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	stale := root.Rank(opts.Mode).Stale(
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		func(t tally.FinalTally) time.Time { return lastActive[t.AuthorEmail] },
+	)
+
+	paths := []string{}
+	for _, s := range stale {
+		paths = append(paths, s.Path)
+
+		if s.Owner.AuthorEmail != "bob@mail.com" {
+			t.Errorf("expected owner of %s to be bob", s.Path)
+		}
+
+		if !s.LastActive.Equal(lastActive["bob@mail.com"]) {
+			t.Errorf("wrong last active time for %s: %v", s.Path, s.LastActive)
+		}
+	}
+
+	expected := []string{"foo/", "foo/bim.txt"}
+	if diff := cmp.Diff(expected, paths); diff != "" {
+		t.Errorf("stale paths are wrong:\n%s", diff)
+	}
+}
@@ -2,8 +2,12 @@
 package tally
 
 import (
+	"cmp"
 	"iter"
+	"math"
+	"path"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/sinclairtarget/git-who/internal/git"
@@ -19,19 +23,54 @@ const (
 	FilesMode
 	LastModifiedMode
 	FirstModifiedMode
+	ActiveDaysMode
+	LinesAddedMode
+	LinesRemovedMode
+	NetLinesMode // Lines added minus lines removed, which may be negative
+	ScoreMode    // Custom score computed from other metrics
 )
 
+// Whether this mode ranks authors by some count of lines.
+func (mode TallyMode) IsLinesMode() bool {
+	return mode == LinesMode ||
+		mode == LinesAddedMode ||
+		mode == LinesRemovedMode ||
+		mode == NetLinesMode
+}
+
 const NoDiffPathname = ".git-who-no-diff-commits"
 
 type TallyOpts struct {
-	Mode        TallyMode
-	Key         func(c git.Commit) string // Unique ID for author
-	CountMerges bool
+	Mode          TallyMode
+	Key           func(c git.Commit) string // Unique ID for author
+	CountMerges   bool
+	FollowRenames bool // Credit edits to renamed files to their latest path
+	Identity      IdentityMode
+	Date          DateMode
+	// Maps a name and email to those of the person they belong to. Used to
+	// merge duplicate identities before keying. May be nil.
+	Merge func(name, email string) (string, string)
+	// Computes the score used to rank authors in ScoreMode. May be nil.
+	Score func(t FinalTally) float64
+	// Commits come from following only first parents, so each merge commit
+	// stands in for the branch it merged and is tallied like any other commit
+	FirstParent bool
+	// Maps the hash of a merge commit to the author of the branch it merged,
+	// who gets credit for the merge instead of its author. May be nil.
+	BranchAuthors map[string]git.Author
+	// Maps the hash of a squash commit to the co-authors named in its message.
+	// The commit's diff is split evenly between its author and co-authors. May
+	// be nil.
+	SquashCoAuthors map[string][]git.Author
 }
 
-// Whether we need --stat and --summary data from git log for this tally mode
+// Whether we need --stat and --summary data from git log for this tally mode.
+// Crediting extensions needs the file paths even when counting commits.
 func (opts TallyOpts) IsDiffMode() bool {
-	return opts.Mode == FilesMode || opts.Mode == LinesMode
+	return opts.Mode == FilesMode ||
+		opts.Mode.IsLinesMode() ||
+		opts.Mode == ScoreMode ||
+		opts.Identity == ExtensionIdentity
 }
 
 // Metrics tallied for a single author while walking git log.
@@ -47,6 +86,11 @@ type FinalTally struct {
 	FileCount       int // Num of file paths in working dir touched by author
 	FirstCommitTime time.Time
 	LastCommitTime  time.Time
+	ActiveDays      int     // Num distinct days on which author committed
+	ActiveWeeks     int     // Num distinct weeks in which author committed
+	LongestStreak   int     // Most consecutive days on which author committed
+	MedianGap       float64 // Median num days between author's active days
+	Score           float64 // Custom score, zero unless tallied with a scorer
 }
 
 func (t FinalTally) SortKey(mode TallyMode) int64 {
@@ -57,23 +101,40 @@ func (t FinalTally) SortKey(mode TallyMode) int64 {
 		return int64(t.FileCount)
 	case LinesMode:
 		return int64(t.LinesAdded + t.LinesRemoved)
+	case LinesAddedMode:
+		return int64(t.LinesAdded)
+	case LinesRemovedMode:
+		return int64(t.LinesRemoved)
+	case NetLinesMode:
+		return int64(t.LinesAdded - t.LinesRemoved)
 	case FirstModifiedMode:
 		return -t.FirstCommitTime.Unix()
 	case LastModifiedMode:
 		return t.LastCommitTime.Unix()
+	case ActiveDaysMode:
+		return int64(t.ActiveDays)
+	case ScoreMode:
+		return int64(math.Round(t.Score))
 	default:
 		panic("unrecognized mode in switch statement")
 	}
 }
 
 func (a FinalTally) Compare(b FinalTally, mode TallyMode) int {
-	aRank := a.SortKey(mode)
-	bRank := b.SortKey(mode)
+	if mode == ScoreMode {
+		// Don't lose the fractional part of the score to rounding
+		if c := cmp.Compare(a.Score, b.Score); c != 0 {
+			return c
+		}
+	} else {
+		aRank := a.SortKey(mode)
+		bRank := b.SortKey(mode)
 
-	if aRank < bRank {
-		return -1
-	} else if bRank < aRank {
-		return 1
+		if aRank < bRank {
+			return -1
+		} else if bRank < aRank {
+			return 1
+		}
 	}
 
 	// Break ties with last edited
@@ -90,8 +151,10 @@ type Tally struct {
 	fileset         map[string]bool
 	firstCommitTime time.Time
 	lastCommitTime  time.Time
+	dayset          map[int]bool // Local dates of commits as day numbers
 	// Can be used to count Tally objs when we don't need to disambiguate
 	numTallied int
+	score      func(t FinalTally) float64 // May be nil
 }
 
 func or(a, b string) string {
@@ -104,7 +167,7 @@ func or(a, b string) string {
 	return a
 }
 
-func unionInPlace(a, b map[string]bool) map[string]bool {
+func unionInPlace[K comparable](a, b map[K]bool) map[K]bool {
 	if a == nil {
 		return b
 	}
@@ -118,6 +181,14 @@ func unionInPlace(a, b map[string]bool) map[string]bool {
 	return union
 }
 
+func orScore(a, b func(t FinalTally) float64) func(t FinalTally) float64 {
+	if a == nil {
+		return b
+	}
+
+	return a
+}
+
 func (a Tally) Combine(b Tally) Tally {
 	return Tally{
 		name:            or(a.name, b.name),
@@ -128,10 +199,20 @@ func (a Tally) Combine(b Tally) Tally {
 		fileset:         unionInPlace(a.fileset, b.fileset),
 		firstCommitTime: timeutils.Min(a.firstCommitTime, b.firstCommitTime),
 		lastCommitTime:  timeutils.Max(a.lastCommitTime, b.lastCommitTime),
+		dayset:          unionInPlace(a.dayset, b.dayset),
 		numTallied:      a.numTallied + b.numTallied,
+		score:           orScore(a.score, b.score),
 	}
 }
 
+// Combines two tallies for the same path, which together still count as only
+// one file changed.
+func (a Tally) combineSamePath(b Tally) Tally {
+	t := a.Combine(b)
+	t.numTallied = min(t.numTallied, 1)
+	return t
+}
+
 func (t Tally) Final() FinalTally {
 	commits := t.numTallied // Not using commitset? Fallback to numTallied
 	if len(t.commitset) > 0 {
@@ -147,7 +228,9 @@ func (t Tally) Final() FinalTally {
 		panic("tally finalized but has no name and no email")
 	}
 
-	return FinalTally{
+	activity := computeActivity(t.dayset)
+
+	final := FinalTally{
 		AuthorName:      t.name,
 		AuthorEmail:     t.email,
 		Commits:         commits,
@@ -156,7 +239,17 @@ func (t Tally) Final() FinalTally {
 		FileCount:       files,
 		FirstCommitTime: t.firstCommitTime,
 		LastCommitTime:  t.lastCommitTime,
+		ActiveDays:      activity.days,
+		ActiveWeeks:     activity.weeks,
+		LongestStreak:   activity.longestStreak,
+		MedianGap:       activity.medianGap,
+	}
+
+	if t.score != nil {
+		final.Score = t.score(final)
 	}
+
+	return final
 }
 
 // author -> path -> tally
@@ -175,9 +268,7 @@ func (left TalliesByPath) Combine(right TalliesByPath) TalliesByPath {
 				rightTally.firstCommitTime = time.Unix(1<<62, 0)
 			}
 
-			t := leftTally.Combine(rightTally)
-			t.numTallied = min(t.numTallied, 1) // Same path
-			rightPathTallies[path] = t
+			rightPathTallies[path] = leftTally.combineSamePath(rightTally)
 		}
 
 		right[key] = rightPathTallies
@@ -193,6 +284,7 @@ func (byPath TalliesByPath) Reduce() map[string]Tally {
 	for key, pathTallies := range byPath {
 		var runningTally Tally
 		runningTally.commitset = map[string]bool{}
+		runningTally.dayset = map[int]bool{}
 		runningTally.firstCommitTime = time.Unix(1<<62, 0)
 
 		for _, tally := range pathTallies {
@@ -207,6 +299,196 @@ func (byPath TalliesByPath) Reduce() map[string]Tally {
 	return tallies
 }
 
+// Returns by-path tallies with every path prefixed with the given directory.
+//
+// Used to merge the tallies for a submodule into those of its superproject.
+// Commits without a diff stay under NoDiffPathname.
+func (byPath TalliesByPath) WithPrefix(prefix string) TalliesByPath {
+	prefixed := TalliesByPath{}
+
+	for key, pathTallies := range byPath {
+		prefixedPathTallies := map[string]Tally{}
+		for p, tally := range pathTallies {
+			if p != NoDiffPathname {
+				p = path.Join(prefix, p)
+			}
+
+			prefixedPathTallies[p] = tally
+		}
+
+		prefixed[key] = prefixedPathTallies
+	}
+
+	return prefixed
+}
+
+// Moves the tallies for the given paths under NoDiffPathname.
+//
+// The commits editing those paths are still counted, but they no longer
+// contribute to lines or files. We use this for submodule paths when we tally
+// the submodule's own history instead.
+func (byPath TalliesByPath) MoveToNoDiff(paths []string) TalliesByPath {
+	for key, pathTallies := range byPath {
+		for _, p := range paths {
+			tally, ok := pathTallies[p]
+			if !ok {
+				continue
+			}
+
+			delete(pathTallies, p)
+
+			tally.added = 0
+			tally.removed = 0
+			tally.numTallied = 0
+
+			noDiffTally, ok := pathTallies[NoDiffPathname]
+			if ok {
+				tally = noDiffTally.Combine(tally)
+			}
+
+			pathTallies[NoDiffPathname] = tally
+		}
+
+		byPath[key] = pathTallies
+	}
+
+	return byPath
+}
+
+// An author's tally for a single path.
+type PathTally struct {
+	Path string
+	FinalTally
+	IsTop bool // Whether no other author ranks higher on this path
+}
+
+// Returns the author's tally for each path they edited, ranked according to
+// mode. The author is the one whose key matches the given key ignoring case.
+func (byPath TalliesByPath) AuthorPaths(
+	author string,
+	mode TallyMode,
+) []PathTally {
+	tallies := map[string]Tally{} // Combined across keys differing in case
+	for key, pathTallies := range byPath {
+		if !strings.EqualFold(key, author) {
+			continue
+		}
+
+		for p, t := range pathTallies {
+			if p == NoDiffPathname {
+				continue
+			}
+
+			if existing, ok := tallies[p]; ok {
+				t = existing.Combine(t)
+			}
+			tallies[p] = t
+		}
+	}
+
+	pathTallies := []PathTally{}
+	for p, t := range tallies {
+		final := t.Final()
+
+		isTop := true
+		for key, otherPathTallies := range byPath {
+			if strings.EqualFold(key, author) {
+				continue
+			}
+
+			other, ok := otherPathTallies[p]
+			if ok && other.Final().Compare(final, mode) > 0 {
+				isTop = false
+				break
+			}
+		}
+
+		pathTallies = append(pathTallies, PathTally{p, final, isTop})
+	}
+
+	slices.SortFunc(pathTallies, func(a, b PathTally) int {
+		return cmp.Or(
+			-a.Compare(b.FinalTally, mode),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+
+	return pathTallies
+}
+
+// Tallies for a group of paths, such as the files in one language.
+type GroupTally struct {
+	Group   string
+	Total   FinalTally   // Over all authors, named after the group
+	Authors []FinalTally // Ranked according to mode
+}
+
+// Groups paths using the given function and tallies each group, both overall
+// and per author. The groups are ranked by their totals according to mode.
+func (byPath TalliesByPath) Groups(
+	group func(p string) string,
+	mode TallyMode,
+) []GroupTally {
+	newTally := func() Tally {
+		return Tally{
+			commitset:       map[string]bool{},
+			dayset:          map[int]bool{},
+			firstCommitTime: time.Unix(1<<62, 0),
+		}
+	}
+
+	totals := map[string]Tally{}
+	authorTallies := map[string]map[string]Tally{} // group -> key -> tally
+	for key, pathTallies := range byPath {
+		for p, t := range pathTallies {
+			if p == NoDiffPathname {
+				continue
+			}
+
+			g := group(p)
+
+			total, ok := totals[g]
+			if !ok {
+				total = newTally()
+				total.name = g
+				total.email = g
+				total.fileset = map[string]bool{}
+				authorTallies[g] = map[string]Tally{}
+			}
+			total = total.Combine(t)
+			if t.numTallied > 0 {
+				// Count each path once even if several authors edited it
+				total.fileset[p] = true
+			}
+			totals[g] = total
+
+			authorTally, ok := authorTallies[g][key]
+			if !ok {
+				authorTally = newTally()
+			}
+			authorTallies[g][key] = authorTally.Combine(t)
+		}
+	}
+
+	groups := []GroupTally{}
+	for g, total := range totals {
+		groups = append(groups, GroupTally{
+			Group:   g,
+			Total:   total.Final(),
+			Authors: Rank(authorTallies[g], mode),
+		})
+	}
+
+	slices.SortFunc(groups, func(a, b GroupTally) int {
+		return cmp.Or(
+			-a.Total.Compare(b.Total, mode),
+			cmp.Compare(a.Group, b.Group),
+		)
+	})
+
+	return groups
+}
+
 func TallyCommits(
 	commits iter.Seq[git.Commit],
 	opts TallyOpts,
@@ -220,7 +502,7 @@ func TallyCommits(
 		tallies = map[string]Tally{}
 
 		// Don't need info about file paths, just count commits and commit time
-		for commit := range commits {
+		for commit := range opts.credited(commits) {
 			if commit.IsMerge && !opts.CountMerges {
 				continue
 			}
@@ -232,9 +514,12 @@ func TallyCommits(
 				tally.name = commit.AuthorName
 				tally.email = commit.AuthorEmail
 				tally.firstCommitTime = commit.Date
+				tally.dayset = map[int]bool{}
+				tally.score = opts.Score
 			}
 
 			tally.numTallied += 1
+			tally.dayset[dayNumber(commit.AuthorLocalDate())] = true
 			tally.firstCommitTime = timeutils.Min(
 				commit.Date,
 				tally.firstCommitTime,
@@ -269,7 +554,7 @@ func TallyCommitsByPath(
 	tallies := TalliesByPath{}
 
 	// Tally over commits
-	for commit := range commits {
+	for commit := range opts.credited(commits) {
 		if commit.IsMerge && !opts.CountMerges {
 			continue
 		}
@@ -295,10 +580,13 @@ func TallyCommitsByPath(
 				tally.email = commit.AuthorEmail
 				tally.firstCommitTime = commit.Date
 				tally.commitset = map[string]bool{}
+				tally.dayset = map[int]bool{}
+				tally.score = opts.Score
 				tally.numTallied = 0 // Don't count toward files changed
 			}
 
 			tally.commitset[commit.ShortHash] = true
+			tally.dayset[dayNumber(commit.AuthorLocalDate())] = true
 			tally.firstCommitTime = timeutils.Min(
 				tally.firstCommitTime,
 				commit.Date,
@@ -317,9 +605,12 @@ func TallyCommitsByPath(
 					tally.email = commit.AuthorEmail
 					tally.firstCommitTime = commit.Date
 					tally.commitset = map[string]bool{}
+					tally.dayset = map[int]bool{}
+					tally.score = opts.Score
 				}
 
 				tally.commitset[commit.ShortHash] = true
+				tally.dayset[dayNumber(commit.AuthorLocalDate())] = true
 				tally.firstCommitTime = timeutils.Min(
 					tally.firstCommitTime,
 					commit.Date,
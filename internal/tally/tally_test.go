@@ -1,12 +1,17 @@
 package tally_test
 
 import (
+	"maps"
+	"path"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/mailmap"
 	"github.com/sinclairtarget/git-who/internal/tally"
 )
 
@@ -69,12 +74,15 @@ func TestTallyCommits(t *testing.T) {
 
 	bob := rankedTallies[0]
 	expected := tally.FinalTally{
-		AuthorName:   "bob",
-		AuthorEmail:  "bob@mail.com",
-		Commits:      1,
-		LinesAdded:   14,
-		LinesRemoved: 3,
-		FileCount:    3,
+		AuthorName:    "bob",
+		AuthorEmail:   "bob@mail.com",
+		Commits:       1,
+		LinesAdded:    14,
+		LinesRemoved:  3,
+		FileCount:     3,
+		ActiveDays:    1,
+		ActiveWeeks:   1,
+		LongestStreak: 1,
 	}
 	if diff := cmp.Diff(expected, bob); diff != "" {
 		t.Errorf("bob's tally is wrong:\n%s", diff)
@@ -82,14 +90,933 @@ func TestTallyCommits(t *testing.T) {
 
 	jim := rankedTallies[1]
 	expected = tally.FinalTally{
-		AuthorName:   "jim",
-		AuthorEmail:  "jim@mail.com",
-		Commits:      1,
-		LinesAdded:   3,
-		LinesRemoved: 1,
-		FileCount:    1,
+		AuthorName:    "jim",
+		AuthorEmail:   "jim@mail.com",
+		Commits:       1,
+		LinesAdded:    3,
+		LinesRemoved:  1,
+		FileCount:     1,
+		ActiveDays:    1,
+		ActiveWeeks:   1,
+		LongestStreak: 1,
 	}
 	if diff := cmp.Diff(expected, jim); diff != "" {
 		t.Errorf("jim's tally is wrong:\n%s", diff)
 	}
 }
+
+func TestTallyCommitsIdentity(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:           "baa",
+			ShortHash:      "baa",
+			AuthorName:     "bob",
+			AuthorEmail:    "bob@mail.com",
+			CommitterName:  "jim",
+			CommitterEmail: "jim@mail.com",
+			Trailers: []git.Trailer{
+				{
+					Key:   git.ReviewedByTrailer,
+					Name:  "jim",
+					Email: "jim@mail.com",
+				},
+				{
+					Key:   git.SignedOffByTrailer,
+					Name:  "bob",
+					Email: "bob@mail.com",
+				},
+			},
+		},
+		git.Commit{
+			Hash:           "bab",
+			ShortHash:      "bab",
+			AuthorName:     "jim",
+			AuthorEmail:    "jim@mail.com",
+			CommitterName:  "jim",
+			CommitterEmail: "jim@mail.com",
+		},
+		git.Commit{
+			Hash:           "bac",
+			ShortHash:      "bac",
+			AuthorName:     "bob",
+			AuthorEmail:    "bob@mail.com",
+			CommitterName:  "jim",
+			CommitterEmail: "jim@mail.com",
+			Trailers: []git.Trailer{
+				{
+					Key:   git.AckedByTrailer,
+					Name:  "jim",
+					Email: "jim@mail.com",
+				},
+				{
+					Key:   git.TestedByTrailer,
+					Name:  "sue",
+					Email: "sue@mail.com",
+				},
+				{
+					Key:   git.SignedOffByTrailer,
+					Name:  "jim",
+					Email: "jim@mail.com",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		identity tally.IdentityMode
+		expected map[string]int
+	}{
+		{
+			name:     "author",
+			identity: tally.AuthorIdentity,
+			expected: map[string]int{"bob@mail.com": 2, "jim@mail.com": 1},
+		},
+		{
+			name:     "committer",
+			identity: tally.CommitterIdentity,
+			expected: map[string]int{"jim@mail.com": 3},
+		},
+		{
+			name:     "author_or_committer",
+			identity: tally.AuthorOrCommitterIdentity,
+			expected: map[string]int{"bob@mail.com": 2, "jim@mail.com": 3},
+		},
+		{
+			name:     "reviewer",
+			identity: tally.ReviewerIdentity,
+			expected: map[string]int{"jim@mail.com": 2, "sue@mail.com": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := tally.TallyOpts{
+				Mode:     tally.CommitMode,
+				Key:      func(c git.Commit) string { return c.AuthorEmail },
+				Identity: test.identity,
+			}
+
+			tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+			if err != nil {
+				t.Fatalf("TallyCommits() returned error: %v", err)
+			}
+
+			commitCounts := map[string]int{}
+			for _, final := range tally.Rank(tallies, opts.Mode) {
+				commitCounts[final.AuthorEmail] = final.Commits
+			}
+
+			if diff := cmp.Diff(test.expected, commitCounts); diff != "" {
+				t.Errorf("commit counts are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+// A skewed committer clock only matters when tallying by committer date
+func TestTallyCommitsCommitterDateInFuture(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:          "baa",
+			ShortHash:     "baa",
+			AuthorName:    "bob",
+			AuthorEmail:   "bob@mail.com",
+			Date:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CommitterDate: time.Now().AddDate(1, 0, 0),
+		},
+		git.Commit{
+			Hash:          "bab",
+			ShortHash:     "bab",
+			AuthorName:    "bob",
+			AuthorEmail:   "bob@mail.com",
+			Date:          time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			CommitterDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		date     tally.DateMode
+		expected int
+	}{
+		{"author_date", tally.AuthorDate, 2},
+		{"committer_date", tally.CommitterDate, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := tally.TallyOpts{
+				Mode: tally.CommitMode,
+				Key:  func(c git.Commit) string { return c.AuthorEmail },
+				Date: test.date,
+			}
+
+			tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+			if err != nil {
+				t.Fatalf("TallyCommits() returned error: %v", err)
+			}
+
+			bob := tallies["bob@mail.com"].Final()
+			if bob.Commits != test.expected {
+				t.Errorf(
+					"expected %d commits but got %d",
+					test.expected,
+					bob.Commits,
+				)
+			}
+		})
+	}
+}
+
+func TestTallyCommitsMerge(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@home.net",
+		},
+		git.Commit{
+			Hash:        "bac",
+			ShortHash:   "bac",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.CommitMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+		Merge: func(name, email string) (string, string) {
+			if email == "bob@home.net" {
+				return "bob", "bob@mail.com"
+			}
+			return name, email
+		},
+	}
+
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	commitCounts := map[string]int{}
+	for _, final := range tally.Rank(tallies, opts.Mode) {
+		commitCounts[final.AuthorName+" "+final.AuthorEmail] = final.Commits
+	}
+
+	expected := map[string]int{"bob bob@mail.com": 2, "jim jim@mail.com": 1}
+	if diff := cmp.Diff(expected, commitCounts); diff != "" {
+		t.Errorf("commit counts are wrong:\n%s", diff)
+	}
+}
+
+func TestTallyCommitsFirstParent(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 4, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			IsMerge:     true,
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "vim.txt", LinesAdded: 8, LinesRemoved: 2},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		branchAuthors map[string]git.Author
+		expected      map[string]int
+	}{
+		{
+			name:     "credit_merger",
+			expected: map[string]int{"bob": 14},
+		},
+		{
+			name: "credit_branch_author",
+			branchAuthors: map[string]git.Author{
+				"bab": git.Author{Name: "jim", Email: "jim@mail.com"},
+			},
+			expected: map[string]int{"bob": 4, "jim": 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := tally.TallyOpts{
+				Mode:          tally.LinesMode,
+				Key:           func(c git.Commit) string { return c.AuthorEmail },
+				FirstParent:   true,
+				BranchAuthors: test.branchAuthors,
+			}
+
+			tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+			if err != nil {
+				t.Fatalf("TallyCommits() returned error: %v", err)
+			}
+
+			lines := map[string]int{}
+			for _, final := range tally.Rank(tallies, opts.Mode) {
+				lines[final.AuthorName] = final.LinesAdded + final.LinesRemoved
+			}
+
+			if diff := cmp.Diff(test.expected, lines); diff != "" {
+				t.Errorf("lines are wrong:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTallyCommitsSquash(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 10, LinesRemoved: 2},
+				git.FileDiff{Path: "vim.txt", LinesAdded: 1, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 3, LinesRemoved: 0},
+			},
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+		SquashCoAuthors: map[string][]git.Author{
+			"baa": []git.Author{
+				git.Author{Name: "jim", Email: "jim@mail.com"},
+				git.Author{Name: "Bob", Email: "BOB@mail.com"}, // Author again
+				git.Author{Name: "kim", Email: "kim@mail.com"},
+			},
+		},
+	}
+
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	type result struct {
+		Commits int
+		Files   int
+		Added   int
+		Removed int
+	}
+
+	results := map[string]result{}
+	for _, final := range tally.Rank(tallies, opts.Mode) {
+		results[final.AuthorName] = result{
+			Commits: final.Commits,
+			Files:   final.FileCount,
+			Added:   final.LinesAdded,
+			Removed: final.LinesRemoved,
+		}
+	}
+
+	expected := map[string]result{
+		"bob": result{Commits: 1, Files: 2, Added: 5, Removed: 1},
+		"jim": result{Commits: 2, Files: 2, Added: 6, Removed: 1},
+		"kim": result{Commits: 1, Files: 2, Added: 3, Removed: 0},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("tallies are wrong:\n%s", diff)
+	}
+}
+
+// The squash author has a mailmap entry and credits their old email as a
+// co-author. Git maps the author but not the co-author, so we have to map the
+// co-author ourselves or they would be credited twice.
+func TestTallyCommitsSquashMailmap(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@work.com", // Already mapped by git log
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 10, LinesRemoved: 2},
+			},
+		},
+	}
+
+	m := mailmap.Mailmap{
+		{ProperName: "Bob", ProperEmail: "bob@work.com", CommitEmail: "bob@home.net"},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+		SquashCoAuthors: map[string][]git.Author{
+			"baa": m.MapAuthors([]git.Author{
+				git.Author{Name: "bobby", Email: "bob@home.net"},
+				git.Author{Name: "kim", Email: "kim@mail.com"},
+			}),
+		},
+	}
+
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	added := map[string]int{}
+	for _, final := range tally.Rank(tallies, opts.Mode) {
+		added[final.AuthorEmail] = final.LinesAdded
+	}
+
+	expected := map[string]int{"bob@work.com": 5, "kim@mail.com": 5}
+	if diff := cmp.Diff(expected, added); diff != "" {
+		t.Errorf("lines added are wrong:\n%s", diff)
+	}
+}
+
+func TestTallyCommitsByExtension(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "main.go", LinesAdded: 4},
+				git.FileDiff{Path: "foo/Bar.GO", LinesAdded: 2},
+				git.FileDiff{Path: "Makefile", LinesAdded: 1},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "main.go", LinesAdded: 3, LinesRemoved: 1},
+			},
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode:     tally.CommitMode,
+		Key:      func(c git.Commit) string { return c.AuthorEmail },
+		Identity: tally.ExtensionIdentity,
+	}
+
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	type counts struct {
+		Commits int
+		Lines   int
+		Files   int
+	}
+
+	byExt := map[string]counts{}
+	for _, final := range tally.Rank(tallies, opts.Mode) {
+		byExt[final.AuthorName] = counts{
+			final.Commits,
+			final.LinesAdded + final.LinesRemoved,
+			final.FileCount,
+		}
+	}
+
+	expected := map[string]counts{
+		".go":      {Commits: 2, Lines: 10, Files: 2},
+		"Makefile": {Commits: 1, Lines: 1, Files: 1},
+	}
+	if diff := cmp.Diff(expected, byExt); diff != "" {
+		t.Errorf("extension tallies are wrong:\n%s", diff)
+	}
+}
+
+func TestRankLinesModes(t *testing.T) {
+	tallies := []tally.FinalTally{
+		tally.FinalTally{AuthorName: "bob", LinesAdded: 10, LinesRemoved: 2},
+		tally.FinalTally{AuthorName: "jim", LinesAdded: 1, LinesRemoved: 20},
+		tally.FinalTally{AuthorName: "tim", LinesAdded: 6, LinesRemoved: 0},
+	}
+
+	tests := []struct {
+		mode     tally.TallyMode
+		expected []string
+		topKey   int64
+	}{
+		{tally.LinesMode, []string{"jim", "bob", "tim"}, 21},
+		{tally.LinesAddedMode, []string{"bob", "tim", "jim"}, 10},
+		{tally.LinesRemovedMode, []string{"jim", "bob", "tim"}, 20},
+		{tally.NetLinesMode, []string{"bob", "tim", "jim"}, 8},
+	}
+
+	for _, test := range tests {
+		ranked := slices.Clone(tallies)
+		slices.SortFunc(ranked, func(a, b tally.FinalTally) int {
+			return -a.Compare(b, test.mode)
+		})
+
+		names := []string{}
+		for _, t := range ranked {
+			names = append(names, t.AuthorName)
+		}
+		if diff := cmp.Diff(test.expected, names); diff != "" {
+			t.Errorf("ranking in mode %d is wrong:\n%s", test.mode, diff)
+		}
+
+		if key := ranked[0].SortKey(test.mode); key != test.topKey {
+			t.Errorf(
+				"expected sort key %d in mode %d but got %d",
+				test.topKey,
+				test.mode,
+				key,
+			)
+		}
+	}
+
+	jim := tallies[1]
+	if key := jim.SortKey(tally.NetLinesMode); key != -19 {
+		t.Errorf("expected net lines of -19 but got %d", key)
+	}
+}
+
+func TestTallyCommitsScore(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 300, LinesRemoved: 0},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "bim.txt", LinesAdded: 1, LinesRemoved: 0},
+				git.FileDiff{Path: "vim.txt", LinesAdded: 1, LinesRemoved: 1},
+			},
+		},
+		git.Commit{
+			Hash:        "bac",
+			ShortHash:   "bac",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "vim.txt", LinesAdded: 1, LinesRemoved: 0},
+			},
+		},
+	}
+
+	score, err := tally.ParseScore("commits*3 + files + lines/100")
+	if err != nil {
+		t.Fatalf("ParseScore() returned error: %v", err)
+	}
+
+	opts := tally.TallyOpts{
+		Mode:  tally.ScoreMode,
+		Key:   func(c git.Commit) string { return c.AuthorEmail },
+		Score: score,
+	}
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	ranked := tally.Rank(tallies, opts.Mode)
+	if len(ranked) != 2 {
+		t.Fatalf("expected 2 tallies but got %d", len(ranked))
+	}
+
+	// jim: 2*3 + 2 + 4/100, bob: 1*3 + 1 + 300/100
+	expected := []float64{8.04, 7}
+	scores := []float64{ranked[0].Score, ranked[1].Score}
+	if diff := cmp.Diff(expected, scores, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("scores are wrong:\n%s", diff)
+	}
+
+	if ranked[0].AuthorName != "jim" {
+		t.Errorf("expected jim to rank first but got %s", ranked[0].AuthorName)
+	}
+
+	if key := ranked[0].SortKey(tally.ScoreMode); key != 8 {
+		t.Errorf("expected sort key of 8 but got %d", key)
+	}
+}
+
+func TestParseScoreUnknownVariable(t *testing.T) {
+	_, err := tally.ParseScore("commits + reviews")
+	if err == nil {
+		t.Errorf("expected error for unknown variable")
+	}
+}
+
+func TestTalliesByPathWithSubmodule(t *testing.T) {
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key: func(c git.Commit) string {
+			return c.AuthorEmail
+		},
+	}
+
+	superCommits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "bim.txt",
+					LinesAdded:   4,
+					LinesRemoved: 0,
+				},
+				git.FileDiff{
+					Path:         "libs/foo",
+					LinesAdded:   1,
+					LinesRemoved: 1,
+				},
+			},
+		},
+	}
+	subCommits := []git.Commit{
+		git.Commit{
+			Hash:        "cab",
+			ShortHash:   "cab",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "bim.txt",
+					LinesAdded:   2,
+					LinesRemoved: 1,
+				},
+			},
+		},
+	}
+
+	superByPath, err := tally.TallyCommitsByPath(
+		slices.Values(superCommits),
+		opts,
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsByPath() returned error: %v", err)
+	}
+
+	subByPath, err := tally.TallyCommitsByPath(
+		slices.Values(subCommits),
+		opts,
+	)
+	if err != nil {
+		t.Fatalf("TallyCommitsByPath() returned error: %v", err)
+	}
+
+	superByPath = superByPath.MoveToNoDiff([]string{"libs/foo"})
+	byPath := subByPath.WithPrefix("libs/foo").Combine(superByPath)
+
+	paths := slices.Sorted(maps.Keys(byPath["bob@mail.com"]))
+	expectedPaths := []string{
+		tally.NoDiffPathname,
+		"bim.txt",
+		"libs/foo/bim.txt",
+	}
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Errorf("paths are wrong:\n%s", diff)
+	}
+
+	bob := byPath.Reduce()["bob@mail.com"].Final()
+	expected := tally.FinalTally{
+		AuthorName:    "bob",
+		AuthorEmail:   "bob@mail.com",
+		Commits:       2,
+		LinesAdded:    6,
+		LinesRemoved:  1,
+		FileCount:     2,
+		ActiveDays:    1,
+		ActiveWeeks:   1,
+		LongestStreak: 1,
+	}
+	if diff := cmp.Diff(expected, bob, cmpopts.IgnoreFields(
+		tally.FinalTally{},
+		"FirstCommitTime",
+		"LastCommitTime",
+	)); diff != "" {
+		t.Errorf("bob's tally is wrong:\n%s", diff)
+	}
+}
+
+func TestTallyCommitsActivity(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	commit := func(hash string, date time.Time, path string) git.Commit {
+		return git.Commit{
+			Hash:        hash,
+			ShortHash:   hash,
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        date,
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: path, LinesAdded: 1},
+			},
+		}
+	}
+
+	// Tallied separately, the way concurrent chunks of git log are
+	chunks := [][]git.Commit{
+		[]git.Commit{
+			commit("baa", day(time.December, 3), "bim.txt"),
+			commit("bab", day(time.December, 5), "vim.txt"),
+			commit("bac", day(time.December, 18), "bim.txt"),
+		},
+		[]git.Commit{
+			commit("bad", day(time.December, 4), "vim.txt"),
+			commit("bae", day(time.December, 5), "bim.txt"),
+			commit("baf", day(time.December, 11), "nim.txt"),
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.ActiveDaysMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	byPath := tally.TalliesByPath{}
+	for _, chunk := range chunks {
+		chunkByPath, err := tally.TallyCommitsByPath(slices.Values(chunk), opts)
+		if err != nil {
+			t.Fatalf("TallyCommitsByPath() returned error: %v", err)
+		}
+
+		byPath = chunkByPath.Combine(byPath)
+	}
+
+	bob := byPath.Reduce()["bob@mail.com"].Final()
+
+	type activity struct {
+		ActiveDays    int
+		ActiveWeeks   int
+		LongestStreak int
+		MedianGap     float64
+	}
+	expected := activity{
+		ActiveDays:    5,
+		ActiveWeeks:   3,
+		LongestStreak: 3,
+		MedianGap:     3.5, // Gaps of 1, 1, 6, and 7 days
+	}
+	got := activity{
+		bob.ActiveDays,
+		bob.ActiveWeeks,
+		bob.LongestStreak,
+		bob.MedianGap,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("bob's activity is wrong:\n%s", diff)
+	}
+
+	if bob.SortKey(tally.ActiveDaysMode) != 5 {
+		t.Errorf(
+			"expected sort key of 5 but got %d",
+			bob.SortKey(tally.ActiveDaysMode),
+		)
+	}
+}
+
+// Days are counted in the author's time zone, not the one we run in
+func TestTallyCommitsActivityAuthorTimeZone(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:         "baa",
+			ShortHash:    "baa",
+			AuthorName:   "bob",
+			AuthorEmail:  "bob@mail.com",
+			Date:         time.Date(2024, time.December, 3, 23, 0, 0, 0, time.UTC),
+			AuthorOffset: 2 * 60 * 60, // 1am on December 4th for bob
+		},
+		git.Commit{
+			Hash:         "bab",
+			ShortHash:    "bab",
+			AuthorName:   "bob",
+			AuthorEmail:  "bob@mail.com",
+			Date:         time.Date(2024, time.December, 4, 10, 0, 0, 0, time.UTC),
+			AuthorOffset: 2 * 60 * 60,
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.ActiveDaysMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	tallies, err := tally.TallyCommits(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	bob := tallies["bob@mail.com"].Final()
+	if bob.ActiveDays != 1 {
+		t.Errorf("expected 1 active day but got %d", bob.ActiveDays)
+	}
+}
+
+func TestAuthorPaths(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "bim.txt", LinesAdded: 4},
+				{Path: "vim.txt", LinesAdded: 8, LinesRemoved: 2},
+			},
+		},
+		git.Commit{
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			FileDiffs: []git.FileDiff{
+				{Path: "vim.txt", LinesAdded: 30, LinesRemoved: 1},
+			},
+		},
+		git.Commit{
+			ShortHash:   "bac",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	byPath, err := tally.TallyCommitsByPath(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommitsByPath() returned error: %v", err)
+	}
+
+	type summary struct {
+		Path    string
+		Commits int
+		Lines   int
+		IsTop   bool
+	}
+
+	summaries := []summary{}
+	for _, p := range byPath.AuthorPaths("BOB@mail.com", tally.LinesMode) {
+		summaries = append(summaries, summary{
+			Path:    p.Path,
+			Commits: p.Commits,
+			Lines:   p.LinesAdded + p.LinesRemoved,
+			IsTop:   p.IsTop,
+		})
+	}
+
+	expected := []summary{
+		{Path: "vim.txt", Commits: 1, Lines: 10, IsTop: false},
+		{Path: "bim.txt", Commits: 1, Lines: 4, IsTop: true},
+	}
+	if diff := cmp.Diff(expected, summaries); diff != "" {
+		t.Errorf("author paths are wrong:\n%s", diff)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "main.go", LinesAdded: 4},
+				git.FileDiff{Path: "README.md", LinesAdded: 20},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{Path: "main.go", LinesAdded: 3, LinesRemoved: 1},
+				git.FileDiff{Path: "foo/bar.go", LinesAdded: 2},
+			},
+		},
+	}
+
+	opts := tally.TallyOpts{
+		Mode: tally.LinesMode,
+		Key:  func(c git.Commit) string { return c.AuthorEmail },
+	}
+
+	byPath, err := tally.TallyCommitsByPath(slices.Values(commits), opts)
+	if err != nil {
+		t.Fatalf("TallyCommitsByPath() returned error: %v", err)
+	}
+
+	groups := byPath.Groups(path.Ext, opts.Mode)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups but got %d", len(groups))
+	}
+
+	md, goGroup := groups[0], groups[1]
+	if md.Group != ".md" || goGroup.Group != ".go" {
+		t.Fatalf("groups ranked wrong: %s, %s", md.Group, goGroup.Group)
+	}
+
+	total := goGroup.Total
+	if total.Commits != 2 || total.LinesAdded != 9 || total.FileCount != 2 {
+		t.Errorf(
+			"wrong total for .go: %d commits, %d lines added, %d files",
+			total.Commits,
+			total.LinesAdded,
+			total.FileCount,
+		)
+	}
+
+	authors := []string{}
+	for _, author := range goGroup.Authors {
+		authors = append(authors, author.AuthorEmail)
+	}
+
+	expected := []string{"jim@mail.com", "bob@mail.com"}
+	if diff := cmp.Diff(expected, authors); diff != "" {
+		t.Errorf(".go authors are wrong:\n%s", diff)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -77,6 +79,7 @@ func (t *TreeNode) Rank(mode TallyMode) *TreeNode {
 					tally.name = childTally.name
 					tally.email = childTally.email
 					tally.commitset = map[string]bool{}
+					tally.dayset = map[int]bool{}
 					tally.firstCommitTime = time.Unix(1<<62, 0)
 				}
 
@@ -92,6 +95,34 @@ func (t *TreeNode) Rank(mode TallyMode) *TreeNode {
 	return t
 }
 
+// Iterates over the nodes under this one that are in the working tree, in path
+// order. Directory paths end with "/".
+func (t *TreeNode) Paths() iter.Seq2[string, *TreeNode] {
+	return func(yield func(string, *TreeNode) bool) {
+		t.walk("", yield)
+	}
+}
+
+func (t *TreeNode) walk(prefix string, yield func(string, *TreeNode) bool) bool {
+	for _, name := range slices.Sorted(maps.Keys(t.Children)) {
+		child := t.Children[name]
+		if !child.InWorkTree {
+			continue
+		}
+
+		p := prefix + name
+		if len(child.Children) > 0 {
+			p += "/"
+		}
+
+		if !yield(p, child) || !child.walk(p, yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
 /*
 * TallyCommitsTree() returns a tree of nodes mirroring the working directory
 * with a tally for each node.
@@ -102,12 +133,19 @@ func TallyCommitsTree(
 	worktreePaths map[string]bool,
 	gitRootPath string,
 ) (*TreeNode, error) {
+	var renames Renames
+	if opts.FollowRenames {
+		commits = TeeRenames(commits, &renames)
+	}
+
 	// Tally paths
 	talliesByPath, err := TallyCommitsByPath(commits, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	talliesByPath = talliesByPath.FollowRenames(renames)
+
 	return TallyCommitsTreeFromPaths(talliesByPath, worktreePaths, gitRootPath)
 }
 
@@ -3,6 +3,7 @@ package tally_test
 import (
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -92,24 +93,30 @@ func TestTallyCommitsTree(t *testing.T) {
 	}
 
 	expected := tally.FinalTally{
-		AuthorName:   "bob",
-		AuthorEmail:  "bob@mail.com",
-		Commits:      2,
-		LinesAdded:   4 + 8 + 23,
-		LinesRemoved: 2,
-		FileCount:    2,
+		AuthorName:    "bob",
+		AuthorEmail:   "bob@mail.com",
+		Commits:       2,
+		LinesAdded:    4 + 8 + 23,
+		LinesRemoved:  2,
+		FileCount:     2,
+		ActiveDays:    1,
+		ActiveWeeks:   1,
+		LongestStreak: 1,
 	}
 	if diff := cmp.Diff(expected, root.Tally); diff != "" {
 		t.Errorf("bob's tally is wrong:\n%s", diff)
 	}
 
 	expected = tally.FinalTally{
-		AuthorName:   "bob",
-		AuthorEmail:  "bob@mail.com",
-		Commits:      2,
-		LinesAdded:   4 + 23,
-		LinesRemoved: 0,
-		FileCount:    1,
+		AuthorName:    "bob",
+		AuthorEmail:   "bob@mail.com",
+		Commits:       2,
+		LinesAdded:    4 + 23,
+		LinesRemoved:  0,
+		FileCount:     1,
+		ActiveDays:    1,
+		ActiveWeeks:   1,
+		LongestStreak: 1,
 	}
 	if diff := cmp.Diff(expected, bimNode.Tally); diff != "" {
 		t.Errorf("bob's second tally is wrong:\n%s", diff)
@@ -132,3 +139,212 @@ func TestTallyCommitsTreeNoCommits(t *testing.T) {
 		)
 	}
 }
+
+func TestTallyCommitsTreeFollowRenames(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:        "baa",
+			ShortHash:   "baa",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Unix(100, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "foo/bim.txt",
+					LinesAdded:   4,
+					LinesRemoved: 0,
+				},
+			},
+		},
+		git.Commit{
+			Hash:        "bab",
+			ShortHash:   "bab",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Unix(200, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:    "bar/bim.txt",
+					OldPath: "foo/bim.txt",
+				},
+			},
+		},
+		git.Commit{
+			Hash:        "bac",
+			ShortHash:   "bac",
+			AuthorName:  "jim",
+			AuthorEmail: "jim@mail.com",
+			Date:        time.Unix(300, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:    "baz/bim.txt",
+					OldPath: "bar/bim.txt",
+				},
+			},
+		},
+		git.Commit{
+			Hash:        "bad",
+			ShortHash:   "bad",
+			AuthorName:  "bob",
+			AuthorEmail: "bob@mail.com",
+			Date:        time.Unix(400, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "baz/bim.txt",
+					LinesAdded:   2,
+					LinesRemoved: 1,
+				},
+			},
+		},
+	}
+
+	worktreeset := map[string]bool{"baz/bim.txt": true}
+	seq := slices.Values(commits)
+	opts := tally.TallyOpts{
+		Mode:          tally.CommitMode,
+		Key:           func(c git.Commit) string { return c.AuthorEmail },
+		FollowRenames: true,
+	}
+
+	root, err := tally.TallyCommitsTree(seq, opts, worktreeset, "")
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	root = root.Rank(opts.Mode)
+
+	if len(root.Children) != 1 {
+		t.Fatalf(
+			"expected root node to have one child but it has %d",
+			len(root.Children),
+		)
+	}
+
+	bazNode, ok := root.Children["baz"]
+	if !ok {
+		t.Fatalf("root node has no \"baz\" child")
+	}
+
+	bimNode, ok := bazNode.Children["bim.txt"]
+	if !ok {
+		t.Fatalf("\"baz\" node has no \"bim.txt\" child")
+	}
+
+	expected := tally.FinalTally{
+		AuthorName:      "bob",
+		AuthorEmail:     "bob@mail.com",
+		Commits:         2,
+		LinesAdded:      4 + 2,
+		LinesRemoved:    1,
+		FileCount:       1,
+		FirstCommitTime: time.Unix(100, 0),
+		LastCommitTime:  time.Unix(400, 0),
+		ActiveDays:      1,
+		ActiveWeeks:     1,
+		LongestStreak:   1,
+	}
+	if diff := cmp.Diff(expected, bimNode.Tally); diff != "" {
+		t.Errorf("bob's tally is wrong:\n%s", diff)
+	}
+}
+
+// Renames should be replayed in log order even when a later commit has an
+// earlier timestamp, e.g. because of a skewed clock or a rebase.
+func TestTallyCommitsTreeFollowRenamesOutOfOrder(t *testing.T) {
+	commits := []git.Commit{
+		git.Commit{
+			Hash:          "baa",
+			ShortHash:     "baa",
+			AuthorName:    "bob",
+			AuthorEmail:   "bob@mail.com",
+			Date:          time.Unix(100, 0),
+			CommitterDate: time.Unix(100, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:         "foo/bim.txt",
+					LinesAdded:   4,
+					LinesRemoved: 0,
+				},
+			},
+		},
+		git.Commit{
+			Hash:          "bab",
+			ShortHash:     "bab",
+			AuthorName:    "jim",
+			AuthorEmail:   "jim@mail.com",
+			Date:          time.Unix(300, 0),
+			CommitterDate: time.Unix(300, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:    "bar/bim.txt",
+					OldPath: "foo/bim.txt",
+				},
+			},
+		},
+		git.Commit{
+			Hash:          "bac",
+			ShortHash:     "bac",
+			AuthorName:    "jim",
+			AuthorEmail:   "jim@mail.com",
+			Date:          time.Unix(200, 0),
+			CommitterDate: time.Unix(200, 0),
+			FileDiffs: []git.FileDiff{
+				git.FileDiff{
+					Path:    "baz/bim.txt",
+					OldPath: "bar/bim.txt",
+				},
+			},
+		},
+	}
+
+	worktreeset := map[string]bool{"baz/bim.txt": true}
+	seq := slices.Values(commits)
+	opts := tally.TallyOpts{
+		Mode:          tally.CommitMode,
+		Key:           func(c git.Commit) string { return c.AuthorEmail },
+		FollowRenames: true,
+	}
+
+	root, err := tally.TallyCommitsTree(seq, opts, worktreeset, "")
+	if err != nil {
+		t.Fatalf("TallyCommits() returned error: %v", err)
+	}
+
+	root = root.Rank(opts.Mode)
+
+	bazNode, ok := root.Children["baz"]
+	if !ok {
+		t.Fatalf("root node has no \"baz\" child")
+	}
+
+	if _, ok := bazNode.Children["bim.txt"]; !ok {
+		t.Fatalf("\"baz\" node has no \"bim.txt\" child")
+	}
+
+	// Bob's commit to foo/bim.txt should have followed the file to baz/
+	if len(root.Children) != 1 {
+		t.Errorf(
+			"expected root node to have one child but it has %d",
+			len(root.Children),
+		)
+	}
+}
+
+func TestRenamesInLogOrder(t *testing.T) {
+	renames := tally.Renames{
+		{From: "b", To: "c", Hash: "bac"},
+		{From: "x", To: "y", Hash: "zzz"},
+		{From: "a", To: "b", Hash: "bab"},
+	}
+
+	ordered := renames.InLogOrder([]string{"baa", "bab", "bac"})
+
+	expected := tally.Renames{
+		{From: "a", To: "b", Hash: "bab"},
+		{From: "b", To: "c", Hash: "bac"},
+		{From: "x", To: "y", Hash: "zzz"},
+	}
+	if diff := cmp.Diff(expected, ordered); diff != "" {
+		t.Errorf("renames are in the wrong order:\n%s", diff)
+	}
+}
@@ -1,6 +1,11 @@
 package timeutils
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 func Max(a, b time.Time) time.Time {
 	if b.Before(a) {
@@ -17,3 +22,40 @@ func Min(a, b time.Time) time.Time {
 		return a
 	}
 }
+
+// Returns the time the given age before now.
+//
+// The age is a whole number followed by a unit: "d" for days, "w" for weeks,
+// "mo" for months, or "y" for years. For example, "6mo".
+func Ago(now time.Time, age string) (time.Time, error) {
+	units := []struct {
+		suffix string
+		years  int
+		months int
+		days   int
+	}{
+		{"mo", 0, 1, 0},
+		{"d", 0, 0, 1},
+		{"w", 0, 0, 7},
+		{"y", 1, 0, 0},
+	}
+
+	for _, unit := range units {
+		numStr, found := strings.CutSuffix(age, unit.suffix)
+		if !found {
+			continue
+		}
+
+		n, err := strconv.Atoi(numStr)
+		if err != nil || n < 0 {
+			break
+		}
+
+		return now.AddDate(-n*unit.years, -n*unit.months, -n*unit.days), nil
+	}
+
+	return now, fmt.Errorf(
+		"invalid age %q: expected a number followed by d, w, mo, or y",
+		age,
+	)
+}
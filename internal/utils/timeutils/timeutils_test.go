@@ -0,0 +1,44 @@
+package timeutils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sinclairtarget/git-who/internal/utils/timeutils"
+)
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2025, 8, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		age      string
+		expected time.Time
+	}{
+		{"30d", time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)},
+		{"2w", time.Date(2025, 8, 17, 12, 0, 0, 0, time.UTC)},
+		{"6mo", time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{"1y", time.Date(2024, 8, 31, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.age, func(t *testing.T) {
+			ago, err := timeutils.Ago(now, test.age)
+			if err != nil {
+				t.Fatalf("Ago() returned error: %v", err)
+			}
+
+			if !ago.Equal(test.expected) {
+				t.Errorf("expected %v but got %v", test.expected, ago)
+			}
+		})
+	}
+}
+
+func TestAgoInvalid(t *testing.T) {
+	for _, age := range []string{"", "6", "mo", "-1d", "6m", "1.5y"} {
+		_, err := timeutils.Ago(time.Now(), age)
+		if err == nil {
+			t.Errorf("expected error for %q", age)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
 	"github.com/sinclairtarget/git-who/internal/subcommands"
 	"github.com/sinclairtarget/git-who/internal/tally"
 	"github.com/sinclairtarget/git-who/internal/utils/flagutils"
@@ -28,11 +30,22 @@ type command struct {
 // If no subcommand was specified, we default to the "table" subcommand.
 func main() {
 	subcommands := map[string]command{ // Available subcommands
-		"dump":  dumpCmd(),
-		"parse": parseCmd(),
-		"table": tableCmd(),
-		"tree":  treeCmd(),
-		"hist":  histCmd(),
+		"dump":       dumpCmd(),
+		"parse":      parseCmd(),
+		"table":      tableCmd(),
+		"tree":       treeCmd(),
+		"hist":       histCmd(),
+		"reviews":    reviewsCmd(),
+		"identities": identitiesCmd(),
+		"mailmap":    mailmapCmd(),
+		"author":     authorCmd(),
+		"files":      filesCmd(),
+		"orphans":    orphansCmd(),
+		"stale":      staleCmd(),
+		"langs":      langsCmd(),
+		"punchcard":  punchcardCmd(),
+		"stats":      statsCmd(),
+		"compare":    compareCmd(),
 	}
 
 	// --- Handle top-level flags ---
@@ -52,7 +65,22 @@ func main() {
 		fmt.Println()
 		fmt.Println("Subcommands:")
 
-		helpSubcommands := []string{"table", "tree", "hist"}
+		helpSubcommands := []string{
+			"table",
+			"tree",
+			"hist",
+			"reviews",
+			"author",
+			"files",
+			"orphans",
+			"stale",
+			"langs",
+			"punchcard",
+			"stats",
+			"compare",
+			"identities",
+			"mailmap",
+		}
 		for _, name := range helpSubcommands {
 			cmd := subcommands[name]
 
@@ -125,12 +153,38 @@ func tableCmd() command {
 	showEmail := flagSet.Bool("e", false, "Show email address of each author")
 	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
 	linesMode := flagSet.Bool("l", false, "Sort by lines added + removed")
+	addedMode := flagSet.Bool("added", false, "Sort by lines added")
+	removedMode := flagSet.Bool("removed", false, "Sort by lines removed")
+	netMode := flagSet.Bool("net", false, "Sort by net lines (added - removed)")
 	filesMode := flagSet.Bool("f", false, "Sort by files changed")
 	firstModifiedMode := flagSet.Bool("c", false, "Sort by first modified (created)")
 	lastModifiedMode := flagSet.Bool("m", false, "Sort by last modified")
+	activeDaysMode := flagSet.Bool(
+		"days",
+		false,
+		"Sort by active days and show active days, weeks, longest streak, and median days between active days",
+	)
+	scoreExpr := flagSet.String("score", "", strings.TrimSpace(`
+Sort by a score computed from an expression like "commits*3 + files + lines/100"
+or from a profile named with git config who.score.<name>
+	`))
 	limit := flagSet.Int("n", 10, "Limit rows in table (set to 0 for no limit)")
+	showPct := flagSet.Bool(
+		"pct",
+		false,
+		"Show each author's share of the total and the cumulative share",
+	)
+	recurseSubmodules := flagSet.Bool(
+		"recurse-submodules",
+		false,
+		"Include the history of checked-out submodules",
+	)
 
 	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+	identityFlags := addIdentityFlags(flagSet, true)
+	repoFlags := addRepoFlags(flagSet)
+	branchFlags := addBranchFlags(flagSet)
 
 	description := "Print out a table showing total contributions by author"
 
@@ -151,33 +205,69 @@ Usage: git-who table [options...] [revisions...] [[--] paths...]
 
 			if !isOnlyOne(
 				*linesMode,
+				*addedMode,
+				*removedMode,
+				*netMode,
 				*filesMode,
 				*lastModifiedMode,
 				*firstModifiedMode,
+				*activeDaysMode,
+				*scoreExpr != "",
 			) {
 				return errors.New("all sort flags are mutually exclusive")
 			}
 
 			if *linesMode {
 				mode = tally.LinesMode
+			} else if *addedMode {
+				mode = tally.LinesAddedMode
+			} else if *removedMode {
+				mode = tally.LinesRemovedMode
+			} else if *netMode {
+				mode = tally.NetLinesMode
 			} else if *filesMode {
 				mode = tally.FilesMode
 			} else if *lastModifiedMode {
 				mode = tally.LastModifiedMode
 			} else if *firstModifiedMode {
 				mode = tally.FirstModifiedMode
+			} else if *activeDaysMode {
+				mode = tally.ActiveDaysMode
+			} else if *scoreExpr != "" {
+				mode = tally.ScoreMode
+			}
+
+			if *showPct && !mode.HasShares() {
+				return errors.New("-pct cannot be used with -m, -c, -net, or -score")
 			}
 
 			if *limit < 0 {
 				return errors.New("-n flag must be a positive integer")
 			}
 
-			revs, pathspecs, err := git.ParseArgs(args)
+			identity, dateMode, err := identityFlags.parse()
 			if err != nil {
 				return err
 			}
 
-			err = checkPathspecs(pathspecs)
+			revs, pathspecs, repos, err := repoFlags.parseArgs(args)
+			if err != nil {
+				return err
+			}
+			defer repos.Close()
+
+			revs, omitCherryPicks, err := branchFlags.apply(
+				revs,
+				repos,
+				*recurseSubmodules,
+			)
+			if err != nil {
+				return err
+			}
+
+			creditBranchAuthor, err := branchFlags.creditBranchAuthor(
+				*filterFlags.firstParent,
+			)
 			if err != nil {
 				return err
 			}
@@ -185,15 +275,28 @@ Usage: git-who table [options...] [revisions...] [[--] paths...]
 			return subcommands.Table(
 				revs,
 				pathspecs,
+				repos,
 				mode,
+				*scoreExpr,
 				*useCsv,
 				*showEmail,
+				*showPct,
 				*countMerges,
+				*recurseSubmodules,
+				identity,
+				*identityFlags.autoMerge,
+				*identityFlags.splitSquashes,
+				dateMode,
 				*limit,
 				*filterFlags.since,
 				*filterFlags.until,
 				filterFlags.authors,
 				filterFlags.nauthors,
+				omitCherryPicks,
+				*filterFlags.firstParent,
+				creditBranchAuthor,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
 			)
 		},
 	}
@@ -206,6 +309,13 @@ func treeCmd() command {
 	showHidden := flagSet.Bool("a", false, "Show files not in working tree (also annotates all files)")
 	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
 	useLines := flagSet.Bool("l", false, "Rank authors by lines added/changed")
+	useAdded := flagSet.Bool("added", false, "Rank authors by lines added")
+	useRemoved := flagSet.Bool("removed", false, "Rank authors by lines removed")
+	useNet := flagSet.Bool(
+		"net",
+		false,
+		"Rank authors by net lines (added - removed)",
+	)
 	useFiles := flagSet.Bool("f", false, "Rank authors by files touched")
 	useFirstModified := flagSet.Bool("c", false, "Rank authors by first commit time (created)")
 	useLastModified := flagSet.Bool(
@@ -213,9 +323,36 @@ func treeCmd() command {
 		false,
 		"Rank authors by last commit time",
 	)
+	scoreExpr := flagSet.String("score", "", strings.TrimSpace(`
+Rank authors by a score computed from an expression like
+"commits*3 + files + lines/100" or from a profile named with git config
+who.score.<name>
+	`))
 	depth := flagSet.Int("d", 0, "Limit on tree depth")
+	showPct := flagSet.Bool(
+		"pct",
+		false,
+		"Show each author's share of the total for the path",
+	)
+	followRenames := flagSet.Bool(
+		"follow",
+		false,
+		"Credit edits made to renamed files to their current path",
+	)
+	recurseSubmodules := flagSet.Bool(
+		"recurse-submodules",
+		false,
+		"Include the history of checked-out submodules",
+	)
+	role := flagSet.String("role", "author", strings.TrimSpace(`
+Credit each path to its top "author" or its top "reviewer"
+	`))
 
 	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+	identityFlags := addIdentityFlags(flagSet, false)
+	repoFlags := addRepoFlags(flagSet)
+	branchFlags := addBranchFlags(flagSet)
 
 	description := "Print out a file tree showing most contributions by path"
 
@@ -232,21 +369,37 @@ Usage: git-who tree [options...] [revisions...] [[--] paths...]
 		flagSet:     flagSet,
 		description: description,
 		run: func(args []string) error {
-			revs, pathspecs, err := git.ParseArgs(args)
+			revs, pathspecs, repos, err := repoFlags.parseArgs(args)
 			if err != nil {
-				return fmt.Errorf("could not parse args: %w", err)
+				return err
 			}
+			defer repos.Close()
 
-			err = checkPathspecs(pathspecs)
+			revs, omitCherryPicks, err := branchFlags.apply(
+				revs,
+				repos,
+				*recurseSubmodules,
+			)
+			if err != nil {
+				return err
+			}
+
+			creditBranchAuthor, err := branchFlags.creditBranchAuthor(
+				*filterFlags.firstParent,
+			)
 			if err != nil {
 				return err
 			}
 
 			if !isOnlyOne(
 				*useLines,
+				*useAdded,
+				*useRemoved,
+				*useNet,
 				*useFiles,
 				*useLastModified,
 				*useFirstModified,
+				*scoreExpr != "",
 			) {
 				return errors.New("all ranking flags are mutually exclusive")
 			}
@@ -254,26 +407,68 @@ Usage: git-who tree [options...] [revisions...] [[--] paths...]
 			mode := tally.CommitMode
 			if *useLines {
 				mode = tally.LinesMode
+			} else if *useAdded {
+				mode = tally.LinesAddedMode
+			} else if *useRemoved {
+				mode = tally.LinesRemovedMode
+			} else if *useNet {
+				mode = tally.NetLinesMode
 			} else if *useFiles {
 				mode = tally.FilesMode
 			} else if *useLastModified {
 				mode = tally.LastModifiedMode
 			} else if *useFirstModified {
 				mode = tally.FirstModifiedMode
+			} else if *scoreExpr != "" {
+				mode = tally.ScoreMode
+			}
+
+			if *showPct && !mode.HasShares() {
+				return errors.New("-pct cannot be used with -m, -c, -net, or -score")
+			}
+
+			identity, dateMode, err := identityFlags.parse()
+			if err != nil {
+				return err
+			}
+
+			switch *role {
+			case "author":
+			case "reviewer":
+				if identity != tally.AuthorIdentity {
+					return errors.New("-role reviewer cannot be used with -by")
+				}
+				identity = tally.ReviewerIdentity
+			default:
+				return fmt.Errorf("unrecognized value for -role: %q", *role)
 			}
 
 			return subcommands.Tree(
 				revs,
 				pathspecs,
+				repos,
 				mode,
+				*scoreExpr,
 				*depth,
 				*showEmail,
 				*showHidden,
+				*showPct,
 				*countMerges,
+				*followRenames,
+				*recurseSubmodules,
+				identity,
+				*identityFlags.autoMerge,
+				*identityFlags.splitSquashes,
+				dateMode,
 				*filterFlags.since,
 				*filterFlags.until,
 				filterFlags.authors,
 				filterFlags.nauthors,
+				omitCherryPicks,
+				*filterFlags.firstParent,
+				creditBranchAuthor,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
 			)
 		},
 	}
@@ -283,11 +478,22 @@ func histCmd() command {
 	flagSet := flag.NewFlagSet("git-who hist", flag.ExitOnError)
 
 	useLines := flagSet.Bool("l", false, "Rank authors by lines added/changed")
+	useAdded := flagSet.Bool("added", false, "Rank authors by lines added")
+	useRemoved := flagSet.Bool("removed", false, "Rank authors by lines removed")
+	useNet := flagSet.Bool(
+		"net",
+		false,
+		"Rank authors by net lines (added - removed)",
+	)
 	useFiles := flagSet.Bool("f", false, "Rank authors by files touched")
 	showEmail := flagSet.Bool("e", false, "Show email address of each author")
 	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
 
 	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+	identityFlags := addIdentityFlags(flagSet, false)
+	repoFlags := addRepoFlags(flagSet)
+	branchFlags := addBranchFlags(flagSet)
 
 	description := "Print out a timeline showing most contributions by date"
 
@@ -304,55 +510,138 @@ Usage: git-who hist [options...] [revisions...] [[--] paths...]
 		flagSet:     flagSet,
 		description: description,
 		run: func(args []string) error {
-			revs, pathspecs, err := git.ParseArgs(args)
+			revs, pathspecs, repos, err := repoFlags.parseArgs(args)
 			if err != nil {
-				return fmt.Errorf("could not parse args: %w", err)
+				return err
 			}
+			defer repos.Close()
 
-			err = checkPathspecs(pathspecs)
+			revs, omitCherryPicks, err := branchFlags.apply(revs, repos, false)
+			if err != nil {
+				return err
+			}
+
+			creditBranchAuthor, err := branchFlags.creditBranchAuthor(
+				*filterFlags.firstParent,
+			)
 			if err != nil {
 				return err
 			}
 
-			if !isOnlyOne(*useLines, *useFiles) {
+			if !isOnlyOne(*useLines, *useAdded, *useRemoved, *useNet, *useFiles) {
 				return errors.New("all ranking flags are mutually exclusive")
 			}
 
 			mode := tally.CommitMode
 			if *useLines {
 				mode = tally.LinesMode
+			} else if *useAdded {
+				mode = tally.LinesAddedMode
+			} else if *useRemoved {
+				mode = tally.LinesRemovedMode
+			} else if *useNet {
+				mode = tally.NetLinesMode
 			} else if *useFiles {
 				mode = tally.FilesMode
 			}
 
+			identity, dateMode, err := identityFlags.parse()
+			if err != nil {
+				return err
+			}
+
 			return subcommands.Hist(
 				revs,
 				pathspecs,
+				repos,
 				mode,
 				*showEmail,
 				*countMerges,
+				identity,
+				*identityFlags.autoMerge,
+				*identityFlags.splitSquashes,
+				dateMode,
 				*filterFlags.since,
 				*filterFlags.until,
 				filterFlags.authors,
 				filterFlags.nauthors,
+				omitCherryPicks,
+				*filterFlags.firstParent,
+				creditBranchAuthor,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
 			)
 		},
 	}
 }
 
-func dumpCmd() command {
-	flagSet := flag.NewFlagSet("git-who dump", flag.ExitOnError)
+func reviewsCmd() command {
+	flagSet := flag.NewFlagSet("git-who reviews", flag.ExitOnError)
 
-	short := flagSet.Bool("s", false, "Use short log")
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	showEmail := flagSet.Bool("e", false, "Show email address of each reviewer")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
+	linesMode := flagSet.Bool("l", false, "Sort by lines added + removed")
+	filesMode := flagSet.Bool("f", false, "Sort by files changed")
+	firstModifiedMode := flagSet.Bool("c", false, "Sort by first review")
+	lastModifiedMode := flagSet.Bool("m", false, "Sort by last review")
+	limit := flagSet.Int("n", 10, "Limit rows in table (set to 0 for no limit)")
 
 	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+	dateFlags := addDateFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out a table showing total contributions reviewed, acked, tested, or
+signed off on by each person
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who reviews [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
 
 	return command{
-		flagSet: flagSet,
+		flagSet:     flagSet,
+		description: description,
 		run: func(args []string) error {
+			mode := tally.CommitMode
+
+			if !isOnlyOne(
+				*linesMode,
+				*filesMode,
+				*lastModifiedMode,
+				*firstModifiedMode,
+			) {
+				return errors.New("all sort flags are mutually exclusive")
+			}
+
+			if *linesMode {
+				mode = tally.LinesMode
+			} else if *filesMode {
+				mode = tally.FilesMode
+			} else if *lastModifiedMode {
+				mode = tally.LastModifiedMode
+			} else if *firstModifiedMode {
+				mode = tally.FirstModifiedMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			_, dateMode, err := dateFlags.parse()
+			if err != nil {
+				return err
+			}
+
 			revs, pathspecs, err := git.ParseArgs(args)
 			if err != nil {
-				return fmt.Errorf("could not parse args: %w", err)
+				return err
 			}
 
 			err = checkPathspecs(pathspecs)
@@ -360,30 +649,70 @@ func dumpCmd() command {
 				return err
 			}
 
-			return subcommands.Dump(
+			return subcommands.Table(
 				revs,
 				pathspecs,
-				*short,
+				subcommands.RepoSet{},
+				mode,
+				"",
+				*useCsv,
+				*showEmail,
+				false,
+				*countMerges,
+				false,
+				tally.ReviewerIdentity,
+				false,
+				false,
+				dateMode,
+				*limit,
 				*filterFlags.since,
 				*filterFlags.until,
 				filterFlags.authors,
 				filterFlags.nauthors,
+				false,
+				*filterFlags.firstParent,
+				false,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
 			)
 		},
 	}
 }
 
-func parseCmd() command {
-	flagSet := flag.NewFlagSet("git-who parse", flag.ExitOnError)
+func authorCmd() command {
+	flagSet := flag.NewFlagSet("git-who author", flag.ExitOnError)
 
-	short := flagSet.Bool("s", false, "Use short log")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
+	limit := flagSet.Int("n", 10, "Limit rows in each list of text output (set to 0 for no limit)")
 
 	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := "Print out everything about a single author's contributions"
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who author [options...] <name|email> [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
 
 	return command{
-		flagSet: flagSet,
+		flagSet:     flagSet,
+		description: description,
 		run: func(args []string) error {
-			revs, pathspecs, err := git.ParseArgs(args)
+			if len(args) == 0 {
+				return errors.New("author subcommand requires a name or email")
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args[1:])
 			if err != nil {
 				return fmt.Errorf("could not parse args: %w", err)
 			}
@@ -393,74 +722,1289 @@ func parseCmd() command {
 				return err
 			}
 
-			return subcommands.Parse(
+			return subcommands.Author(
+				args[0],
 				revs,
 				pathspecs,
-				*short,
+				*useJson,
+				*countMerges,
+				*limit,
 				*filterFlags.since,
 				*filterFlags.until,
 				filterFlags.authors,
 				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
 			)
 		},
 	}
 }
 
-// -^---------------------------------------------------------------------------
+func filesCmd() command {
+	flagSet := flag.NewFlagSet("git-who files", flag.ExitOnError)
 
-func configureLogging(level slog.Level) {
-	handler := slog.NewTextHandler(
-		os.Stderr,
-		&slog.HandlerOptions{
-			Level: level,
-		},
+	author := flagSet.String("author", "", "List files edited by this author (required)")
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	linesMode := flagSet.Bool("l", false, "Sort by lines added + removed")
+	firstModifiedMode := flagSet.Bool("c", false, "Sort by first modified (created)")
+	lastModifiedMode := flagSet.Bool("m", false, "Sort by last modified")
+	onlyWorkingTree := flagSet.Bool(
+		"wtree",
+		false,
+		"Only list files still in the working tree",
 	)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
-}
+	onlyTop := flagSet.Bool(
+		"top",
+		false,
+		"Only list files where the author ranks first by the sort metric",
+	)
+	followRenames := flagSet.Bool(
+		"follow",
+		false,
+		"Credit edits made to renamed files to their current path",
+	)
+	limit := flagSet.Int("n", 0, "Limit rows in table (set to 0 for no limit)")
 
-// Used to check mutual exclusion.
-func isOnlyOne(flags ...bool) bool {
-	var foundOne bool
-	for _, f := range flags {
-		if f {
-			if foundOne {
-				return false
-			}
+	filterFlags := addNonAuthorFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
 
-			foundOne = true
-		}
+	description := "Print out the files edited by a single author"
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who files -author <name|email> [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
 	}
 
-	return true
-}
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if len(*author) == 0 {
+				return errors.New("files subcommand requires -author")
+			}
 
-type filterFlags struct {
-	since    *string
-	until    *string
-	authors  flagutils.SliceFlag
-	nauthors flagutils.SliceFlag
-}
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
 
-func addFilterFlags(set *flag.FlagSet) *filterFlags {
-	flags := filterFlags{
-		since: set.String("since", "", strings.TrimSpace(`
-Only count commits after the given date. See git-commit(1) for valid date formats
-		`)),
-		until: set.String("until", "", strings.TrimSpace(`
-Only count commits before the given date. See git-commit(1) for valid date formats
-		`)),
+			if !isOnlyOne(*linesMode, *firstModifiedMode, *lastModifiedMode) {
+				return errors.New("all sort flags are mutually exclusive")
+			}
+
+			mode := tally.CommitMode
+			if *linesMode {
+				mode = tally.LinesMode
+			} else if *lastModifiedMode {
+				mode = tally.LastModifiedMode
+			} else if *firstModifiedMode {
+				mode = tally.FirstModifiedMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Files(
+				revs,
+				pathspecs,
+				*author,
+				mode,
+				*useCsv,
+				*useJson,
+				*onlyWorkingTree,
+				*onlyTop,
+				*followRenames,
+				*limit,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
 	}
+}
 
-	set.Var(&flags.authors, "author", strings.TrimSpace(`
-Only count commits by these authors. Can be specified multiple times
-	`))
+func orphansCmd() command {
+	flagSet := flag.NewFlagSet("git-who orphans", flag.ExitOnError)
 
-	set.Var(&flags.nauthors, "nauthor", strings.TrimSpace(`
-Exclude commits by these authors. Can be specified multiple times
+	var departedAuthors flagutils.SliceFlag
+	flagSet.Var(&departedAuthors, "author", strings.TrimSpace(`
+Treat this author (name or email) as departed. Can be specified multiple times
+	`))
+	inactiveSince := flagSet.String("inactive-since", "", strings.TrimSpace(`
+Treat authors with no commits in this long (e.g. 30d, 2w, 6mo, 1y) as departed
 	`))
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	showEmail := flagSet.Bool("e", false, "Tell authors apart by email address")
+	linesMode := flagSet.Bool("l", false, "Measure ownership by lines added + removed")
+	filesMode := flagSet.Bool("f", false, "Measure ownership by files touched")
+	followRenames := flagSet.Bool(
+		"follow",
+		false,
+		"Credit edits made to renamed files to their current path",
+	)
+	limit := flagSet.Int("n", 0, "Limit rows in table (set to 0 for no limit)")
 
-	return &flags
+	filterFlags := addNonAuthorFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out the paths mostly owned by departed or inactive authors
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who orphans (-author <name|email>... | -inactive-since <age>) [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if len(departedAuthors) == 0 && len(*inactiveSince) == 0 {
+				return errors.New(
+					"orphans subcommand requires -author or -inactive-since",
+				)
+			}
+
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
+
+			if !isOnlyOne(*linesMode, *filesMode) {
+				return errors.New("all mode flags are mutually exclusive")
+			}
+
+			mode := tally.CommitMode
+			if *linesMode {
+				mode = tally.LinesMode
+			} else if *filesMode {
+				mode = tally.FilesMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Orphans(
+				revs,
+				pathspecs,
+				departedAuthors,
+				*inactiveSince,
+				mode,
+				*useCsv,
+				*useJson,
+				*showEmail,
+				*followRenames,
+				*limit,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+func staleCmd() command {
+	flagSet := flag.NewFlagSet("git-who stale", flag.ExitOnError)
+
+	inactiveSince := flagSet.String("inactive-since", "6mo", strings.TrimSpace(`
+List paths whose top contributor has no commits in this long (e.g. 30d, 2w, 6mo, 1y)
+	`))
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	showEmail := flagSet.Bool("e", false, "Tell authors apart by email address")
+	useLines := flagSet.Bool("l", false, "Rank authors by lines added/changed")
+	useFiles := flagSet.Bool("f", false, "Rank authors by files touched")
+	useFirstModified := flagSet.Bool("c", false, "Rank authors by first commit time (created)")
+	useLastModified := flagSet.Bool(
+		"m",
+		false,
+		"Rank authors by last commit time (modified)",
+	)
+	followRenames := flagSet.Bool(
+		"follow",
+		false,
+		"Credit edits made to renamed files to their current path",
+	)
+	limit := flagSet.Int("n", 0, "Limit rows in table (set to 0 for no limit)")
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out the paths whose top contributors are no longer active
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who stale [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
+
+			if !isOnlyOne(
+				*useLines,
+				*useFiles,
+				*useFirstModified,
+				*useLastModified,
+			) {
+				return errors.New("all ranking flags are mutually exclusive")
+			}
+
+			mode := tally.CommitMode
+			if *useLines {
+				mode = tally.LinesMode
+			} else if *useFiles {
+				mode = tally.FilesMode
+			} else if *useFirstModified {
+				mode = tally.FirstModifiedMode
+			} else if *useLastModified {
+				mode = tally.LastModifiedMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Stale(
+				revs,
+				pathspecs,
+				*inactiveSince,
+				mode,
+				*useCsv,
+				*useJson,
+				*showEmail,
+				*followRenames,
+				*limit,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+func langsCmd() command {
+	flagSet := flag.NewFlagSet("git-who langs", flag.ExitOnError)
+
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	showEmail := flagSet.Bool("e", false, "Show email address of each author")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
+	commitMode := flagSet.Bool("commits", false, "Sort by number of commits")
+	filesMode := flagSet.Bool("f", false, "Sort by files changed")
+	limit := flagSet.Int(
+		"n",
+		5,
+		"Limit authors listed per language (set to 0 for no limit)",
+	)
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := "Print out contributions broken down by language"
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who langs [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
+
+			if !isOnlyOne(*commitMode, *filesMode) {
+				return errors.New("all sort flags are mutually exclusive")
+			}
+
+			mode := tally.LinesMode
+			if *commitMode {
+				mode = tally.CommitMode
+			} else if *filesMode {
+				mode = tally.FilesMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Langs(
+				revs,
+				pathspecs,
+				mode,
+				*showEmail,
+				*useCsv,
+				*useJson,
+				*countMerges,
+				*limit,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+func punchcardCmd() command {
+	flagSet := flag.NewFlagSet("git-who punchcard", flag.ExitOnError)
+
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits")
+
+	filterFlags := addNonAuthorFilterFlags(flagSet)
+	flagSet.Var(&filterFlags.authors, "author", strings.TrimSpace(`
+Show a separate punchcard for this author. Can be specified multiple times
+	`))
+
+	description := strings.TrimSpace(`
+Print out when commits are made, by day of the week and hour of the day
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who punchcard [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Punchcard(
+				revs,
+				pathspecs,
+				*useCsv,
+				*useJson,
+				*countMerges,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+			)
+		},
+	}
+}
+
+func statsCmd() command {
+	flagSet := flag.NewFlagSet("git-who stats", flag.ExitOnError)
+
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	useJson := flagSet.Bool("json", false, "Output as JSON")
+	showEmail := flagSet.Bool("e", false, "Tell authors apart by email address")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
+	linesMode := flagSet.Bool("l", false, "Measure by lines added + removed")
+	filesMode := flagSet.Bool("f", false, "Measure by files changed")
+	overTime := flagSet.Bool(
+		"over-time",
+		false,
+		"Show stats for each time period like hist instead of each directory",
+	)
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out how concentrated contributions are among authors
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who stats [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if !isOnlyOne(*useCsv, *useJson) {
+				return errors.New("-csv and -json are mutually exclusive")
+			}
+
+			if !isOnlyOne(*linesMode, *filesMode) {
+				return errors.New("all mode flags are mutually exclusive")
+			}
+
+			mode := tally.CommitMode
+			if *linesMode {
+				mode = tally.LinesMode
+			} else if *filesMode {
+				mode = tally.FilesMode
+			}
+
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Stats(
+				revs,
+				pathspecs,
+				mode,
+				*showEmail,
+				*useCsv,
+				*useJson,
+				*overTime,
+				*countMerges,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+func compareCmd() command {
+	flagSet := flag.NewFlagSet("git-who compare", flag.ExitOnError)
+
+	useCsv := flagSet.Bool("csv", false, "Output as csv")
+	showEmail := flagSet.Bool("e", false, "Show email address of each author")
+	countMerges := flagSet.Bool("merges", false, "Count merge commits toward commit total")
+	linesMode := flagSet.Bool("l", false, "Compare lines added + removed")
+	addedMode := flagSet.Bool("added", false, "Compare lines added")
+	removedMode := flagSet.Bool("removed", false, "Compare lines removed")
+	filesMode := flagSet.Bool("f", false, "Compare files changed")
+	activeDaysMode := flagSet.Bool("days", false, "Compare active days")
+	limit := flagSet.Int("n", 0, "Limit rows (set to 0 for no limit)")
+	sinceA := flagSet.String("since-a", "", "Start of the first period to compare")
+	untilA := flagSet.String("until-a", "", "End of the first period to compare")
+	sinceB := flagSet.String("since-b", "", "Start of the second period to compare")
+	untilB := flagSet.String("until-b", "", "End of the second period to compare")
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out how each author's contributions changed between two revision ranges
+or time periods
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who compare [options...] <revision A> <revision B> [[--] paths...]
+       git-who compare [options...] -since-a <date> -since-b <date> [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			if !isOnlyOne(
+				*linesMode,
+				*addedMode,
+				*removedMode,
+				*filesMode,
+				*activeDaysMode,
+			) {
+				return errors.New("all mode flags are mutually exclusive")
+			}
+
+			mode := tally.CommitMode
+			if *linesMode {
+				mode = tally.LinesMode
+			} else if *addedMode {
+				mode = tally.LinesAddedMode
+			} else if *removedMode {
+				mode = tally.LinesRemovedMode
+			} else if *filesMode {
+				mode = tally.FilesMode
+			} else if *activeDaysMode {
+				mode = tally.ActiveDaysMode
+			}
+
+			if *limit < 0 {
+				return errors.New("-n flag must be a positive integer")
+			}
+
+			comparePeriods := len(*sinceA) > 0 ||
+				len(*untilA) > 0 ||
+				len(*sinceB) > 0 ||
+				len(*untilB) > 0
+
+			revsA, revsB, pathspecs, err := parseCompareArgs(args, comparePeriods)
+			if err != nil {
+				return err
+			}
+
+			// Per-side dates take precedence over -since and -until
+			sideA := subcommands.CompareSide{
+				Revs:  revsA,
+				Since: *filterFlags.since,
+				Until: *filterFlags.until,
+			}
+			sideB := sideA
+			sideB.Revs = revsB
+
+			if !comparePeriods {
+				sideA.Name = args[0]
+				sideB.Name = args[1]
+			}
+
+			if len(*sinceA) > 0 {
+				sideA.Since = *sinceA
+			}
+			if len(*untilA) > 0 {
+				sideA.Until = *untilA
+			}
+			if len(*sinceB) > 0 {
+				sideB.Since = *sinceB
+			}
+			if len(*untilB) > 0 {
+				sideB.Until = *untilB
+			}
+
+			return subcommands.Compare(
+				sideA,
+				sideB,
+				pathspecs,
+				mode,
+				*showEmail,
+				*useCsv,
+				*countMerges,
+				*limit,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+// Splits the args to the "compare" subcommand into the revisions for each side
+// and the pathspecs.
+//
+// Unless we are comparing time periods, the first two args are the revisions
+// or ranges to compare. We parse them one at a time because git rev-parse turns
+// a range into more than one revision. When comparing time periods, any
+// revisions given apply to both sides.
+func parseCompareArgs(
+	args []string,
+	comparePeriods bool,
+) (revsA []string, revsB []string, pathspecs []string, err error) {
+	if comparePeriods {
+		revs, pathspecs, err := git.ParseArgs(args)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not parse args: %w", err)
+		}
+
+		err = checkPathspecs(pathspecs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return revs, revs, pathspecs, nil
+	}
+
+	if len(args) < 2 || args[0] == "--" || args[1] == "--" {
+		return nil, nil, nil, errors.New(
+			"compare needs two revisions or ranges, or dates given with " +
+				"-since-a, -until-a, -since-b, or -until-b",
+		)
+	}
+
+	sides := [][]string{}
+	for _, arg := range args[:2] {
+		revs, paths, err := git.ParseArgs([]string{arg})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not parse args: %w", err)
+		}
+
+		if len(paths) > 0 {
+			return nil, nil, nil, fmt.Errorf(
+				"not a revision or range: \"%s\"",
+				arg,
+			)
+		}
+
+		sides = append(sides, revs)
+	}
+
+	pathspecs = []string{}
+	rest := args[2:]
+	if len(rest) > 0 && rest[0] == "--" {
+		rest = rest[1:]
+	}
+
+	if len(rest) > 0 {
+		_, pathspecs, err = git.ParseArgs(append([]string{"--"}, rest...))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not parse args: %w", err)
+		}
+	}
+
+	err = checkPathspecs(pathspecs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return sides[0], sides[1], pathspecs, nil
+}
+
+func identitiesCmd() command {
+	flagSet := flag.NewFlagSet("git-who identities", flag.ExitOnError)
+
+	proposeMailmap := flagSet.Bool(
+		"propose-mailmap",
+		false,
+		"Print .mailmap entries merging each cluster instead",
+	)
+
+	filterFlags := addFilterFlags(flagSet)
+	repoFlags := addRepoFlags(flagSet)
+
+	description := strings.TrimSpace(`
+Print out clusters of author identities that likely belong to the same person
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who identities [options...] [revisions...] [[--] paths...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			revs, pathspecs, repos, err := repoFlags.parseArgs(args)
+			if err != nil {
+				return err
+			}
+			defer repos.Close()
+
+			return subcommands.Identities(
+				revs,
+				pathspecs,
+				repos,
+				*proposeMailmap,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+			)
+		},
+	}
+}
+
+func mailmapCmd() command {
+	flagSet := flag.NewFlagSet("git-who mailmap", flag.ExitOnError)
+
+	check := flagSet.Bool(
+		"check",
+		false,
+		"Only print problems, exiting with an error if there are any",
+	)
+
+	description := strings.TrimSpace(`
+Print out the commits matched by each mailmap entry and any problems with the
+mailmap
+	`)
+
+	flagSet.Usage = func() {
+		fmt.Println(strings.TrimSpace(`
+Usage: git-who mailmap [options...] [revisions...]
+		`))
+		fmt.Println(description)
+		fmt.Println()
+		flagSet.PrintDefaults()
+	}
+
+	return command{
+		flagSet:     flagSet,
+		description: description,
+		run: func(args []string) error {
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			if len(pathspecs) > 0 {
+				return errors.New("mailmap subcommand does not take paths")
+			}
+
+			return subcommands.Mailmap(revs, *check)
+		},
+	}
+}
+
+func dumpCmd() command {
+	flagSet := flag.NewFlagSet("git-who dump", flag.ExitOnError)
+
+	short := flagSet.Bool("s", false, "Use short log")
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	return command{
+		flagSet: flagSet,
+		run: func(args []string) error {
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Dump(
+				revs,
+				pathspecs,
+				*short,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+func parseCmd() command {
+	flagSet := flag.NewFlagSet("git-who parse", flag.ExitOnError)
+
+	short := flagSet.Bool("s", false, "Use short log")
+
+	filterFlags := addFilterFlags(flagSet)
+	diffFlags := addDiffFlags(flagSet)
+
+	return command{
+		flagSet: flagSet,
+		run: func(args []string) error {
+			revs, pathspecs, err := git.ParseArgs(args)
+			if err != nil {
+				return fmt.Errorf("could not parse args: %w", err)
+			}
+
+			err = checkPathspecs(pathspecs)
+			if err != nil {
+				return err
+			}
+
+			return subcommands.Parse(
+				revs,
+				pathspecs,
+				*short,
+				*filterFlags.since,
+				*filterFlags.until,
+				filterFlags.authors,
+				filterFlags.nauthors,
+				*filterFlags.firstParent,
+				filterFlags.ignoreRevsFiles,
+				diffFlags.toOpts(*filterFlags.firstParent),
+			)
+		},
+	}
+}
+
+// -^---------------------------------------------------------------------------
+
+func configureLogging(level slog.Level) {
+	handler := slog.NewTextHandler(
+		os.Stderr,
+		&slog.HandlerOptions{
+			Level: level,
+		},
+	)
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+}
+
+// Used to check mutual exclusion.
+func isOnlyOne(flags ...bool) bool {
+	var foundOne bool
+	for _, f := range flags {
+		if f {
+			if foundOne {
+				return false
+			}
+
+			foundOne = true
+		}
+	}
+
+	return true
+}
+
+type filterFlags struct {
+	since           *string
+	until           *string
+	authors         flagutils.SliceFlag
+	nauthors        flagutils.SliceFlag
+	ignoreRevsFiles flagutils.SliceFlag
+	firstParent     *bool
+}
+
+func addFilterFlags(set *flag.FlagSet) *filterFlags {
+	flags := addNonAuthorFilterFlags(set)
+
+	set.Var(&flags.authors, "author", strings.TrimSpace(`
+Only count commits by these authors. Can be specified multiple times
+	`))
+
+	return flags
+}
+
+// Like addFilterFlags, but for subcommands that use -author for something
+// else.
+func addNonAuthorFilterFlags(set *flag.FlagSet) *filterFlags {
+	flags := filterFlags{
+		since: set.String("since", "", strings.TrimSpace(`
+Only count commits after the given date. See git-commit(1) for valid date formats
+		`)),
+		until: set.String("until", "", strings.TrimSpace(`
+Only count commits before the given date. See git-commit(1) for valid date formats
+		`)),
+	}
+
+	set.Var(&flags.nauthors, "nauthor", strings.TrimSpace(`
+Exclude commits by these authors. Can be specified multiple times
+	`))
+
+	set.Var(&flags.ignoreRevsFiles, "ignore-revs-file", strings.TrimSpace(`
+Skip commits listed in this file, in addition to any configured with
+blame.ignoreRevsFile. Can be specified multiple times
+	`))
+
+	flags.firstParent = set.Bool("first-parent", false, strings.TrimSpace(`
+Follow only the first parent of merge commits, so that each merge counts once
+as the change it landed
+	`))
+
+	return &flags
+}
+
+type repoFlags struct {
+	repos    flagutils.SliceFlag
+	manifest *string
+	mailmap  *string
+}
+
+func addRepoFlags(set *flag.FlagSet) *repoFlags {
+	var flags repoFlags
+
+	set.Var(&flags.repos, "repo", strings.TrimSpace(`
+Tally this repository instead of the one in the working directory. Can be
+specified multiple times to combine repositories
+	`))
+
+	flags.manifest = set.String("manifest", "", strings.TrimSpace(`
+Tally the repositories listed in this file, one per line, each optionally
+followed by revisions and paths
+	`))
+
+	flags.mailmap = set.String("mailmap", "", strings.TrimSpace(`
+Mailmap file applied in every repository given with -repo or -manifest, in
+addition to any mailmap.file in your Git configuration
+	`))
+
+	return &flags
+}
+
+// Splits the revisions from the pathspecs given a list of args, unless we are
+// tallying repositories given with -repo or -manifest. In that case the args
+// are instead parsed separately in each repository.
+func (f repoFlags) parseArgs(
+	args []string,
+) (revs []string, pathspecs []string, _ subcommands.RepoSet, err error) {
+	var repos subcommands.RepoSet
+
+	for _, p := range f.repos {
+		repos.Repos = append(repos.Repos, subcommands.Repo{Path: p})
+	}
+
+	if len(*f.manifest) > 0 {
+		manifestRepos, err := subcommands.ReadManifest(*f.manifest)
+		if err != nil {
+			return nil, nil, repos, err
+		}
+
+		if len(manifestRepos) == 0 {
+			return nil, nil, repos, errors.New("manifest lists no repositories")
+		}
+
+		repos.Repos = append(repos.Repos, manifestRepos...)
+	}
+
+	if len(*f.mailmap) > 0 {
+		if repos.IsEmpty() {
+			return nil, nil, repos, errors.New(
+				"-mailmap can only be used with -repo or -manifest",
+			)
+		}
+
+		p, err := filepath.Abs(*f.mailmap)
+		if err != nil {
+			return nil, nil, repos, err
+		}
+
+		err = repos.SetMailmap(p)
+		if err != nil {
+			return nil, nil, repos, err
+		}
+	}
+
+	if !repos.IsEmpty() {
+		repos.Args = args
+		return nil, nil, repos, nil
+	}
+
+	revs, pathspecs, err = git.ParseArgs(args)
+	if err != nil {
+		return nil, nil, repos, fmt.Errorf("could not parse args: %w", err)
+	}
+
+	err = checkPathspecs(pathspecs)
+	if err != nil {
+		return nil, nil, repos, err
+	}
+
+	return revs, pathspecs, repos, nil
+}
+
+type branchFlags struct {
+	onlyBranch   *string
+	branchAuthor *bool
+}
+
+func addBranchFlags(set *flag.FlagSet) *branchFlags {
+	return &branchFlags{
+		onlyBranch: set.String("only-branch", "", strings.TrimSpace(`
+Only count commits on the branch that are not on this base branch, leaving out
+commits cherry-picked from the base
+		`)),
+		branchAuthor: set.Bool("branch-author", false, strings.TrimSpace(`
+With -first-parent, credit each merge to the author of the branch it merged
+instead of to whoever merged it
+		`)),
+	}
+}
+
+// Whether to credit merges to the authors of the branches they merged, which
+// only makes sense when following first parents.
+func (f branchFlags) creditBranchAuthor(firstParent bool) (bool, error) {
+	if *f.branchAuthor && !firstParent {
+		return false, errors.New("-branch-author can only be used with -first-parent")
+	}
+
+	return *f.branchAuthor, nil
+}
+
+// If -only-branch was given, turns the revisions into the symmetric difference
+// between the base and the branch, which is the single revision given or HEAD.
+// The returned bool says whether to also leave out cherry-picked commits with
+// cmd.LogFilters.OmitCherryPicks, which keeps only the branch side.
+func (f branchFlags) apply(
+	revs []string,
+	repos subcommands.RepoSet,
+	recurseSubmodules bool,
+) ([]string, bool, error) {
+	base := *f.onlyBranch
+	if len(base) == 0 {
+		return revs, false, nil
+	}
+
+	if !repos.IsEmpty() {
+		return nil, false, errors.New(
+			"-only-branch cannot be used with -repo or -manifest",
+		)
+	}
+
+	if recurseSubmodules {
+		return nil, false, errors.New(
+			"-only-branch cannot be used with -recurse-submodules",
+		)
+	}
+
+	if len(revs) > 1 {
+		return nil, false, errors.New(
+			"-only-branch takes at most one branch to compare against the base",
+		)
+	}
+
+	branch := "HEAD"
+	if len(revs) == 1 {
+		branch = revs[0]
+	}
+
+	baseRevs, paths, err := git.ParseArgs([]string{base})
+	if err != nil {
+		return nil, false, fmt.Errorf("could not parse -only-branch: %w", err)
+	}
+
+	if len(paths) > 0 || len(baseRevs) != 1 {
+		return nil, false, fmt.Errorf(
+			"-only-branch must name a single revision: \"%s\"",
+			base,
+		)
+	}
+
+	return []string{baseRevs[0] + "..." + branch}, true, nil
+}
+
+type diffFlags struct {
+	ignoreSpace      bool
+	ignoreBlankLines bool
+}
+
+func addDiffFlags(set *flag.FlagSet) *diffFlags {
+	var flags diffFlags
+
+	ignoreSpaceUsage := "Ignore whitespace when counting lines added/removed"
+	set.BoolVar(&flags.ignoreSpace, "w", false, ignoreSpaceUsage)
+	set.BoolVar(&flags.ignoreSpace, "ignore-space", false, ignoreSpaceUsage)
+
+	set.BoolVar(
+		&flags.ignoreBlankLines,
+		"ignore-blank-lines",
+		false,
+		"Ignore blank lines when counting lines added/removed",
+	)
+
+	return &flags
+}
+
+// When following only first parents, merge commits are diffed against their
+// first parent so that they carry the changes they landed.
+func (f diffFlags) toOpts(firstParent bool) cmd.DiffOpts {
+	return cmd.DiffOpts{
+		IgnoreSpace:       f.ignoreSpace,
+		IgnoreBlankLines:  f.ignoreBlankLines,
+		FirstParentMerges: firstParent,
+	}
+}
+
+type identityFlags struct {
+	by            *string
+	date          *string
+	autoMerge     *bool
+	splitSquashes *bool
+	allowExt      bool
+}
+
+// Adds the -by flag and friends. Crediting file extensions with "-by ext" is
+// only allowed if allowExt is set.
+func addIdentityFlags(set *flag.FlagSet, allowExt bool) *identityFlags {
+	flags := addDateFlags(set)
+	flags.allowExt = allowExt
+	if allowExt {
+		flags.by = set.String("by", "author", strings.TrimSpace(`
+Credit commits to their "author", "committer", or "author-or-committer", or
+to the file extensions they edit with "ext"
+		`))
+	} else {
+		flags.by = set.String("by", "author", strings.TrimSpace(`
+Credit commits to their "author", "committer", or "author-or-committer"
+		`))
+	}
+	flags.autoMerge = set.Bool("auto-merge", false, strings.TrimSpace(`
+Merge identities that likely belong to the same person, as listed by the
+"identities" subcommand
+	`))
+	flags.splitSquashes = set.Bool("split-squashes", false, strings.TrimSpace(`
+Split the changes in each squash-merged pull request evenly between its author
+and the co-authors named in its message
+	`))
+
+	return flags
+}
+
+// Like addIdentityFlags, but for subcommands that decide who gets credit
+// themselves.
+func addDateFlags(set *flag.FlagSet) *identityFlags {
+	return &identityFlags{
+		date: set.String("date", "author", strings.TrimSpace(`
+Date commits by their "author" or "committer" timestamp
+		`)),
+	}
+}
+
+func (f identityFlags) parse() (tally.IdentityMode, tally.DateMode, error) {
+	var identity tally.IdentityMode
+	by := "author"
+	if f.by != nil {
+		by = *f.by
+	}
+
+	switch by {
+	case "author":
+		identity = tally.AuthorIdentity
+	case "committer":
+		identity = tally.CommitterIdentity
+	case "author-or-committer":
+		identity = tally.AuthorOrCommitterIdentity
+	case "ext":
+		if !f.allowExt {
+			return identity, tally.AuthorDate, errors.New(
+				"-by ext can only be used with the table subcommand",
+			)
+		}
+
+		identity = tally.ExtensionIdentity
+	default:
+		return identity, tally.AuthorDate, fmt.Errorf(
+			"unrecognized value for -by: %q",
+			by,
+		)
+	}
+
+	if identity == tally.ExtensionIdentity && f.autoMerge != nil && *f.autoMerge {
+		return identity, tally.AuthorDate, errors.New(
+			"-auto-merge cannot be used with -by ext",
+		)
+	}
+
+	splitSquashes := f.splitSquashes != nil && *f.splitSquashes
+	if identity != tally.AuthorIdentity && splitSquashes {
+		return identity, tally.AuthorDate, errors.New(
+			"-split-squashes can only be used with -by author",
+		)
+	}
+
+	var dateMode tally.DateMode
+	switch *f.date {
+	case "author":
+		dateMode = tally.AuthorDate
+	case "committer":
+		dateMode = tally.CommitterDate
+	default:
+		return identity, dateMode, fmt.Errorf(
+			"unrecognized value for -date: %q",
+			*f.date,
+		)
+	}
+
+	return identity, dateMode, nil
 }
 
 /*
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/subcommands"
+)
+
+func TestBranchFlagsApply(t *testing.T) {
+	baseRevs, _, err := git.ParseArgs([]string{"HEAD"})
+	if err != nil {
+		t.Fatalf("could not parse HEAD: %v", err)
+	}
+
+	base := "HEAD"
+	f := branchFlags{onlyBranch: &base}
+
+	tests := []struct {
+		name     string
+		revs     []string
+		expected []string
+	}{
+		{"no_revs", []string{}, []string{baseRevs[0] + "...HEAD"}},
+		{"one_rev", []string{"abc"}, []string{baseRevs[0] + "...abc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revs, omitCherryPicks, err := f.apply(
+				test.revs,
+				subcommands.RepoSet{},
+				false,
+			)
+			if err != nil {
+				t.Fatalf("apply() returned error: %v", err)
+			}
+
+			if diff := cmp.Diff(test.expected, revs); diff != "" {
+				t.Errorf("revisions are wrong:\n%s", diff)
+			}
+
+			if !omitCherryPicks {
+				t.Errorf("expected cherry-picks to be omitted")
+			}
+		})
+	}
+
+	_, _, err = f.apply([]string{"abc", "def"}, subcommands.RepoSet{}, false)
+	if err == nil {
+		t.Errorf("expected error given two revisions")
+	}
+}
@@ -0,0 +1,34 @@
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `author` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestAuthor < Minitest::Test
+  def test_author_by_name
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'author', 'Benoit Chesneau'
+    refute_empty(stdout_s)
+  end
+
+  def test_author_by_email
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'author', '-n 0', 'bchesneau@gmail.com'
+    refute_empty(stdout_s)
+  end
+
+  def test_author_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'author', '--json', 'bchesneau@gmail.com', '--', 'gunicorn/'
+    data = JSON.parse(stdout_s)
+    assert_equal data['email'], 'bchesneau@gmail.com'
+    refute_empty(data['files'])
+  end
+
+  def test_author_unknown
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'author', 'nobody@example.com' }
+  end
+end
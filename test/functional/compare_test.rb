@@ -0,0 +1,54 @@
+require 'csv'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `compare` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestCompare < Minitest::Test
+  MODE_FLAGS = ['', '-l', '--added', '--removed', '-f', '--days']
+  EMAIL_FLAGS = ['', '-e']
+  OUTPUT_FLAGS = ['', '--csv']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    EMAIL_FLAGS,
+    OUTPUT_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_compare_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+      stdout_s = cmd.run 'compare', *flags, 'HEAD~2', 'HEAD'
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_compare_ranges_with_path
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'compare', 'HEAD~2..HEAD~1', 'HEAD~1..HEAD', '--', '.'
+    assert stdout_s
+  end
+
+  def test_compare_periods
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run(
+      'compare',
+      '--csv',
+      '--until-a 2024-12-25',
+      '--since-b 2024-12-25',
+    )
+    data = CSV.parse(stdout_s, headers: true)
+    refute_empty(data)
+  end
+
+  def test_compare_one_rev
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'compare', 'HEAD' }
+  end
+
+  def test_compare_time_mode
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'compare', '-m', 'HEAD~2', 'HEAD' }
+  end
+end
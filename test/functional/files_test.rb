@@ -0,0 +1,43 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `files` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestFiles < Minitest::Test
+  MODE_FLAGS = ['', '-l', '-c', '-m']
+  FILTER_FLAGS = ['', '--wtree', '--top', '--follow']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    FILTER_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_files_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+      cmd.run 'files', '--author bchesneau@gmail.com', *flags
+    end
+  end
+
+  def test_files_csv
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'files', '--author bchesneau@gmail.com', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    refute_empty(data)
+  end
+
+  def test_files_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'files', '--author bchesneau@gmail.com', '--json', '-n 5'
+    data = JSON.parse(stdout_s)
+    assert_equal data.length, 5
+  end
+
+  def test_files_no_author
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'files' }
+  end
+end
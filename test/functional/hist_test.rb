@@ -7,7 +7,7 @@ require 'lib/repo'
 # validity of the output. We just try to hit as many codepaths as we can to
 # check that the program doesn't error out.
 class TestHist < Minitest::Test
-  MODE_FLAGS = ['', '-f', '-l']
+  MODE_FLAGS = ['', '-f', '-l', '--added', '--removed', '--net']
   EMAIL_FLAGS = ['', '-e']
   MERGES_FLAGS = ['', '--merges']
 
@@ -22,6 +22,24 @@ class TestHist < Minitest::Test
     refute_empty(stdout_s)
   end
 
+  def test_hist_only_branch
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'hist', '--only-branch', 'HEAD~1'
+    refute_empty(stdout_s)
+  end
+
+  def test_hist_branch_author
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'hist', '--first-parent', '--branch-author'
+    refute_empty(stdout_s)
+  end
+
+  def test_hist_split_squashes
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'hist', '--split-squashes'
+    refute_empty(stdout_s)
+  end
+
   all_flag_combos = GitWho.generate_args_cartesian_product([
     MODE_FLAGS,
     EMAIL_FLAGS,
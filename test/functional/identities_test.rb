@@ -0,0 +1,43 @@
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `identities` subcommand and the --auto-merge option. Like the
+# other tests for the subcommands, we just try to hit codepaths. The test repo
+# may not have any duplicate identities, so the output may be empty.
+class TestIdentities < Minitest::Test
+  SUBCOMMANDS = ['table', 'tree', 'hist']
+  MODE_FLAGS = ['', '-l']
+
+  def test_identities_no_flags
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    cmd.run 'identities'
+  end
+
+  def test_identities_propose_mailmap
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    cmd.run 'identities', '--propose-mailmap'
+  end
+
+  def test_identities_repos
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    cmd.run 'identities', "--repo #{TestRepo.path} --repo #{BigRepo.path}"
+  end
+
+  SUBCOMMANDS.each do |subcommand|
+    MODE_FLAGS.each do |flags|
+      define_method("test_#{subcommand}_auto_merge_(#{flags})") do
+        cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+        stdout_s = cmd.run subcommand, '--auto-merge', flags
+        refute_empty(stdout_s)
+      end
+    end
+  end
+
+  def test_table_auto_merge_by_committer
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--auto-merge', '--by committer'
+    refute_empty(stdout_s)
+  end
+end
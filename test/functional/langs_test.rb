@@ -0,0 +1,42 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `langs` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestLangs < Minitest::Test
+  MODE_FLAGS = ['', '--commits', '-f']
+  EMAIL_FLAGS = ['', '-e']
+  LIMIT_FLAGS = ['', '-n 0']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    EMAIL_FLAGS,
+    LIMIT_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_langs_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+      stdout_s = cmd.run 'langs', *flags
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_langs_csv
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'langs', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    refute_empty(data)
+  end
+
+  def test_langs_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'langs', '--json', '-n 1'
+    data = JSON.parse(stdout_s)
+    refute_empty(data)
+    data.each { |lang| assert_operator lang['authors'].length, :<=, 1 }
+  end
+end
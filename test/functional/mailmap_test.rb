@@ -271,6 +271,34 @@ class TestMailmap < Minitest::Test
       assert_equal data[3]['commits'], '110'
     end
   end
+
+  def test_mailmap_report
+    mailmap_path = Pathname.new(BigRepo.path) / ".mailmap"
+    File.write(mailmap_path, LOCAL_MAILMAP)
+
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'mailmap'
+    assert_match(/\.mailmap:1: /, stdout_s)
+  ensure
+    File.delete(mailmap_path)
+  end
+
+  def test_mailmap_check
+    mailmap_path = Pathname.new(BigRepo.path) / ".mailmap"
+    File.write(mailmap_path, LOCAL_MAILMAP + BROKEN_MAILMAP)
+
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    err = assert_raises(GitWhoError) { cmd.run 'mailmap', '--check' }
+    assert_match(/problem/, err.message)
+  ensure
+    File.delete(mailmap_path)
+  end
+
+  def test_mailmap_check_no_mailmap
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'mailmap', '--check'
+    assert_match(/No mailmap found/, stdout_s)
+  end
 end
 
 LOCAL_MAILMAP = <<~HEREDOC
@@ -282,3 +310,8 @@ HEREDOC
 GLOBAL_MAILMAP = <<~HEREDOC
   Randall Leeds <randall@bleeds.info> <randall.leeds@gmail.com>
 HEREDOC
+
+BROKEN_MAILMAP = <<~HEREDOC
+  Nobody <nobody@example.com
+  Nobody <nobody@example.com>
+HEREDOC
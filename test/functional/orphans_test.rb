@@ -0,0 +1,54 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `orphans` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestOrphans < Minitest::Test
+  MODE_FLAGS = ['', '-l', '-f']
+  DEPARTED_FLAGS = [
+    '--author bchesneau@gmail.com',
+    '--inactive-since 1y',
+    '--author bchesneau@gmail.com --inactive-since 6mo',
+  ]
+  EMAIL_FLAGS = ['', '-e']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    DEPARTED_FLAGS,
+    EMAIL_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_orphans_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+      cmd.run 'orphans', *flags
+    end
+  end
+
+  def test_orphans_csv
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'orphans', '--author bchesneau@gmail.com', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    refute_empty(data)
+  end
+
+  def test_orphans_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'orphans', '--author bchesneau@gmail.com', '--json'
+    data = JSON.parse(stdout_s)
+    refute_empty(data)
+  end
+
+  def test_orphans_no_authors
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'orphans' }
+  end
+
+  def test_orphans_bad_age
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'orphans', '--inactive-since 6' }
+  end
+end
@@ -0,0 +1,42 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `punchcard` subcommand. Like the other tests for the
+# subcommands, we mostly just try to hit codepaths.
+class TestPunchcard < Minitest::Test
+  AUTHOR_FLAGS = ['', '--author benoitc', '--author benoitc --author Randall']
+  MERGES_FLAGS = ['', '--merges']
+  SINCE_FLAGS = ['', '--since 2015-01-01']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    AUTHOR_FLAGS,
+    MERGES_FLAGS,
+    SINCE_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_punchcard_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+      stdout_s = cmd.run 'punchcard', *flags
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_punchcard_csv
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'punchcard', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    assert_equal data.length, 7 * 24
+  end
+
+  def test_punchcard_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'punchcard', '--json', '--author benoitc'
+    data = JSON.parse(stdout_s)
+    assert_equal data.length, 1
+    assert_equal data[0]['weekdays'].length, 7
+  end
+end
@@ -0,0 +1,71 @@
+require 'minitest/autorun'
+require 'tmpdir'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for combining several repositories with --repo and --manifest. Like the
+# other tests for the subcommands, we just try to hit codepaths.
+class TestRepos < Minitest::Test
+  SUBCOMMANDS = ['table', 'tree', 'hist']
+  MODE_FLAGS = ['', '-l']
+
+  def repo_args
+    "--repo #{TestRepo.path} --repo #{BigRepo.path}"
+  end
+
+  SUBCOMMANDS.each do |subcommand|
+    MODE_FLAGS.each do |flags|
+      define_method("test_#{subcommand}_repos_(#{flags})") do
+        Dir.mktmpdir do |dir|
+          cmd = GitWho.new(GitWho.built_bin_path, dir)
+          stdout_s = cmd.run subcommand, repo_args, flags
+          refute_empty(stdout_s)
+        end
+      end
+    end
+  end
+
+  def test_table_repos_no_concurrent
+    Dir.mktmpdir do |dir|
+      cmd = GitWho.new(GitWho.built_bin_path, dir)
+      stdout_s = cmd.run 'table', repo_args, '-l', n_procs: 1
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_table_manifest
+    Dir.mktmpdir do |dir|
+      manifest_path = File.join(dir, 'manifest.txt')
+      File.write(manifest_path, <<~MANIFEST)
+        # Test manifest
+        #{TestRepo.path}
+        #{BigRepo.path} HEAD -- gunicorn/
+      MANIFEST
+
+      cmd = GitWho.new(GitWho.built_bin_path, dir)
+      stdout_s = cmd.run 'table', "--manifest #{manifest_path}"
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_table_repos_mailmap
+    Dir.mktmpdir do |dir|
+      mailmap_path = File.join(dir, 'mailmap')
+      File.write(mailmap_path, "Bob <bob@example.com> <bob@work.com>\n")
+
+      cmd = GitWho.new(GitWho.built_bin_path, dir)
+      stdout_s = cmd.run 'table', repo_args, "--mailmap #{mailmap_path}"
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_table_repos_duplicate
+    Dir.mktmpdir do |dir|
+      cmd = GitWho.new(GitWho.built_bin_path, dir)
+      assert_raises(GitWhoError) do
+        cmd.run 'table', "--repo #{TestRepo.path} --repo #{TestRepo.path}"
+      end
+    end
+  end
+end
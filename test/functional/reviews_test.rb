@@ -0,0 +1,39 @@
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# This set of tests for the `reviews` subcommand does nothing to check the
+# validity of the output. We just try to hit as many codepaths as we can to
+# check that the program doesn't error out. The test repo may not have any
+# review trailers, so the output may be empty.
+class TestReviews < Minitest::Test
+  MODE_FLAGS = ['', '-c', '-m', '-f', '-l']
+  EMAIL_FLAGS = ['', '-e']
+  CSV_FLAGS = ['', '--csv']
+  DATE_FLAGS = ['', '--date committer']
+
+  def test_reviews_no_flags
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    cmd.run 'reviews'
+  end
+
+  def test_tree_role_reviewer
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    cmd.run 'tree', '--role', 'reviewer'
+  end
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    EMAIL_FLAGS,
+    CSV_FLAGS,
+    DATE_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    test_name = "test_reviews_(#{flags.join ','})"
+    define_method(test_name) do
+      cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+      cmd.run 'reviews', *flags
+    end
+  end
+end
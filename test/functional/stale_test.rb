@@ -0,0 +1,45 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `stale` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestStale < Minitest::Test
+  MODE_FLAGS = ['', '-l', '-f', '-c', '-m']
+  AGE_FLAGS = ['', '--inactive-since 2w', '--inactive-since 1y']
+  EMAIL_FLAGS = ['', '-e']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    AGE_FLAGS,
+    EMAIL_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_stale_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+      cmd.run 'stale', *flags
+    end
+  end
+
+  def test_stale_csv
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'stale', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    refute_empty(data)
+  end
+
+  def test_stale_json
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    stdout_s = cmd.run 'stale', '--json', '-n 5'
+    data = JSON.parse(stdout_s)
+    assert_equal data.length, 5
+  end
+
+  def test_stale_bad_age
+    cmd = GitWho.new(GitWho.built_bin_path, BigRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'stale', '--inactive-since 1.5y' }
+  end
+end
@@ -0,0 +1,47 @@
+require 'csv'
+require 'json'
+require 'minitest/autorun'
+
+require 'lib/cmd'
+require 'lib/repo'
+
+# Tests for the `stats` subcommand. Like the other tests for the subcommands,
+# we mostly just try to hit codepaths.
+class TestStats < Minitest::Test
+  MODE_FLAGS = ['', '-l', '-f']
+  OVER_TIME_FLAGS = ['', '--over-time']
+  OUTPUT_FLAGS = ['', '--csv', '--json']
+
+  all_flag_combos = GitWho.generate_args_cartesian_product([
+    MODE_FLAGS,
+    OVER_TIME_FLAGS,
+    OUTPUT_FLAGS,
+  ])
+  all_flag_combos.each do |flags|
+    define_method("test_stats_(#{flags.join ','})") do
+      cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+      stdout_s = cmd.run 'stats', *flags
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_stats_csv_overall_first
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'stats', '--csv'
+    data = CSV.parse(stdout_s, headers: true)
+    assert_equal data[0]['scope'], '(all)'
+  end
+
+  def test_stats_json
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'stats', '--json', '--over-time'
+    data = JSON.parse(stdout_s)
+    refute_empty(data)
+    assert data[0].key?('period')
+  end
+
+  def test_stats_csv_and_json
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'stats', '--csv', '--json' }
+  end
+end
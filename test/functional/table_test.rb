@@ -1,3 +1,6 @@
+require 'pathname'
+require 'tmpdir'
+
 require 'minitest/autorun'
 
 require 'lib/cmd'
@@ -7,7 +10,8 @@ require 'lib/repo'
 # validity of the output. We just try to hit as many codepaths as we can to
 # check that the program doesn't error out.
 class TestTable < Minitest::Test
-  MODE_FLAGS = ['', '-c', '-f', '-l', '-m']
+  MODE_FLAGS = ['', '-c', '-f', '-l', '-m', '--days', '--added', '--removed', '--net',
+                '--score commits*3+files+lines/100']
   EMAIL_FLAGS = ['', '-e']
   MERGES_FLAGS = ['', '--merges']
   LIMIT_FLAGS = ['', '-n 5']
@@ -23,6 +27,112 @@ class TestTable < Minitest::Test
     refute_empty(stdout_s)
   end
 
+  def test_table_pct
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--pct', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_pct_csv
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--pct', '--csv', '-n 1'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_pct_time_mode
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'table', '--pct', '-m' }
+  end
+
+  def test_table_score_csv
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--score', 'commits*3+files', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_score_unspaced_subtraction
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--score', 'commits-files', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_score_invalid
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'table', '--score', 'commits*+files' }
+  end
+
+  def test_table_score_unknown_profile
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'table', '--score', 'nope' }
+  end
+
+  def test_table_score_profile
+    Dir.mktmpdir do |dir|
+      git_config_path = Pathname.new(dir) / "config"
+      File.write(git_config_path, "[who \"score\"]\n\timpact = commits*3+files")
+
+      cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+      stdout_s = cmd.run(
+        'table',
+        '--score',
+        'impact',
+        git_config_path: git_config_path,
+      )
+      refute_empty(stdout_s)
+    end
+  end
+
+  def test_table_only_branch
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--only-branch', 'HEAD~1', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_only_branch_submodules
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) do
+      cmd.run 'table', '--only-branch', 'HEAD~1', '--recurse-submodules'
+    end
+  end
+
+  def test_table_first_parent
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--first-parent', '-l', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_branch_author
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--first-parent', '--branch-author', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_branch_author_without_first_parent
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) do
+      cmd.run 'table', '--branch-author'
+    end
+  end
+
+  def test_table_split_squashes
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--split-squashes', '-l', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_split_squashes_by_committer
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) do
+      cmd.run 'table', '--split-squashes', '--by', 'committer'
+    end
+  end
+
+  def test_table_recurse_submodules
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--recurse-submodules', '-l'
+    refute_empty(stdout_s)
+  end
+
   all_flag_combos = GitWho.generate_args_cartesian_product([
     MODE_FLAGS,
     EMAIL_FLAGS,
@@ -52,4 +162,31 @@ class TestTable < Minitest::Test
       refute_empty(stdout_s)
     end
   end
+
+  def test_table_days_csv
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--days', '--csv'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_by_ext
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'table', '--by ext', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_table_by_ext_auto_merge
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'table', '--by ext', '--auto-merge' }
+  end
+
+  def test_tree_by_ext
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'tree', '--by ext' }
+  end
+
+  def test_hist_by_ext
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'hist', '--by ext' }
+  end
 end
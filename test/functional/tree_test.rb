@@ -8,7 +8,8 @@ require 'lib/repo'
 # check that the program doesn't error out.
 class TestTree < Minitest::Test
   SHOW_ALL_FLAGS = ['', '-a']
-  MODE_FLAGS = ['', '-c', '-f', '-l', '-m']
+  MODE_FLAGS = ['', '-c', '-f', '-l', '-m', '--added', '--removed', '--net',
+                '--score commits*3+files+lines/100']
   EMAIL_FLAGS = ['', '-e']
   MERGES_FLAGS = ['', '--merges']
 
@@ -23,6 +24,41 @@ class TestTree < Minitest::Test
     refute_empty(stdout_s)
   end
 
+  def test_tree_pct
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'tree', '--pct', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_tree_pct_time_mode
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    assert_raises(GitWhoError) { cmd.run 'tree', '--pct', '-m' }
+  end
+
+  def test_tree_only_branch
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'tree', '--only-branch', 'HEAD~1', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_tree_branch_author
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'tree', '--first-parent', '--branch-author', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_tree_split_squashes
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'tree', '--split-squashes', '-l'
+    refute_empty(stdout_s)
+  end
+
+  def test_tree_recurse_submodules
+    cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
+    stdout_s = cmd.run 'tree', '--recurse-submodules'
+    refute_empty(stdout_s)
+  end
+
   def test_tree_subdir
     cmd = GitWho.new(GitWho.built_bin_path, TestRepo.path)
     stdout_s = cmd.run 'tree', 'file-rename'
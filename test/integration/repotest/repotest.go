@@ -3,6 +3,8 @@ package repotest
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -15,3 +17,35 @@ func UseTestRepo(t *testing.T) {
 		t.Fatalf(msg, err)
 	}
 }
+
+// Clones the SHA-256 test repo from its bundle into a temporary directory and
+// changes the working directory to the clone for the duration of the test.
+//
+// The bundle is built by test/repos/make-sha256-repo.sh.
+func UseSHA256TestRepo(t *testing.T) {
+	bundlePath, err := filepath.Abs("../../repos/sha256-repo.bundle")
+	if err != nil {
+		t.Fatalf("could not find SHA-256 test repo bundle: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("could not get working directory: %v", err)
+	}
+
+	dir := t.TempDir()
+	out, err := exec.Command("git", "clone", "--quiet", bundlePath, dir).
+		CombinedOutput()
+	if err != nil {
+		t.Fatalf("error cloning SHA-256 test repo: %v\n%s", err, out)
+	}
+
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatalf("error changing working directory to clone: %v", err)
+	}
+
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
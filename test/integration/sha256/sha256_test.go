@@ -0,0 +1,161 @@
+// This file contains tests for analyzing repositories that use the SHA-256
+// object format.
+//
+// These tests run against a clone of the SHA-256 test repo bundle.
+
+package sha256_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sinclairtarget/git-who/internal/git"
+	"github.com/sinclairtarget/git-who/internal/git/cmd"
+	"github.com/sinclairtarget/git-who/internal/git/config"
+	rev "github.com/sinclairtarget/git-who/internal/git/revision"
+	"github.com/sinclairtarget/git-who/test/integration/repotest"
+)
+
+const rootCommit = "43d33629b7364b135697c004857f26c2e6bae74283b221e5c0abd858b95d3d07"
+const reviewedCommit = "0e844486bf762cd784a32856296bf4bbfb83fda2693523531a5a6bd3ce6c943f"
+const headCommit = "d498af508b660f15ccfafca3eefafe6510a31144fbb1aebc06b761fcca53f0ae"
+
+func setUp(t *testing.T) {
+	repotest.UseSHA256TestRepo(t)
+}
+
+func TestParseArgs(t *testing.T) {
+	setUp(t)
+
+	revs, pathspecs, err := git.ParseArgs([]string{"root..HEAD", "README.md"})
+	if err != nil {
+		t.Fatalf("ParseArgs() returned error: %v", err)
+	}
+
+	expRevs := []string{headCommit, "^" + rootCommit}
+	if diff := cmp.Diff(expRevs, revs); diff != "" {
+		t.Errorf("ParseArgs() revs are wrong:\n%s", diff)
+	}
+
+	expPaths := []string{"README.md"}
+	if diff := cmp.Diff(expPaths, pathspecs); diff != "" {
+		t.Errorf("ParseArgs() pathspecs are wrong:\n%s", diff)
+	}
+}
+
+func TestCommits(t *testing.T) {
+	setUp(t)
+
+	configFiles, err := config.DetectSupplementalFiles(".", nil)
+	if err != nil {
+		t.Fatalf("DetectSupplementalFiles() returned error: %v", err)
+	}
+
+	if configFiles.ObjectFormat != rev.SHA256 {
+		t.Fatalf(
+			"expected object format to be %s but got %s",
+			rev.SHA256,
+			configFiles.ObjectFormat,
+		)
+	}
+
+	commits, finish := git.CommitsWithOpts(
+		context.Background(),
+		[]string{"HEAD"},
+		[]string{},
+		cmd.LogFilters{},
+		true,
+		cmd.DiffOpts{},
+		configFiles,
+	)
+	collected := slices.Collect(commits)
+	err = finish()
+	if err != nil {
+		t.Fatalf("error iterating commits: %v", err)
+	}
+
+	if len(collected) != 4 {
+		t.Fatalf("expected 4 commits but found %d", len(collected))
+	}
+
+	if collected[0].Hash != rootCommit {
+		t.Errorf(
+			"expected first commit to have hash %s but got %s",
+			rootCommit,
+			collected[0].Hash,
+		)
+	}
+
+	reviewed := collected[2]
+	if len(reviewed.Trailers) != 1 || reviewed.Trailers[0].Name != "Bob" {
+		t.Errorf("expected commit to be reviewed by Bob: %v", reviewed.Trailers)
+	}
+
+	last := collected[3]
+	if last.Hash != headCommit {
+		t.Errorf(
+			"expected last commit to have hash %s but got %s",
+			headCommit,
+			last.Hash,
+		)
+	}
+
+	if len(last.FileDiffs) != 1 || !last.FileDiffs[0].IsRename() {
+		t.Errorf("expected last commit to be a rename: %v", last.FileDiffs)
+	}
+}
+
+func TestRevList(t *testing.T) {
+	setUp(t)
+
+	revs, err := git.RevList(
+		context.Background(),
+		[]string{"HEAD"},
+		[]string{},
+		cmd.LogFilters{},
+	)
+	if err != nil {
+		t.Fatalf("RevList() returned error: %v", err)
+	}
+
+	if len(revs) != 4 {
+		t.Fatalf("expected 4 revs but got %d", len(revs))
+	}
+
+	for _, r := range revs {
+		if !rev.IsFullHash(r, rev.SHA256) {
+			t.Errorf("expected %s to be a full SHA-256 hash", r)
+		}
+	}
+}
+
+// In a SHA-256 repo, a 40-character hash in the ignore revs file is an
+// abbreviation, not a full hash.
+func TestIgnoreRevs(t *testing.T) {
+	setUp(t)
+
+	contents := rootCommit[:7] + "\n" + reviewedCommit[:40] + "\n"
+	err := os.WriteFile(".git-blame-ignore-revs", []byte(contents), 0o644)
+	if err != nil {
+		t.Fatalf("could not write ignore revs file: %v", err)
+	}
+
+	configFiles, err := config.DetectSupplementalFiles(".", nil)
+	if err != nil {
+		t.Fatalf("DetectSupplementalFiles() returned error: %v", err)
+	}
+
+	ignoreRevs, err := configFiles.IgnoreRevs(context.Background())
+	if err != nil {
+		t.Fatalf("IgnoreRevs() returned error: %v", err)
+	}
+
+	expected := []string{rootCommit, reviewedCommit}
+	if diff := cmp.Diff(expected, ignoreRevs); diff != "" {
+		t.Errorf("ignore revs are wrong:\n%s", diff)
+	}
+}
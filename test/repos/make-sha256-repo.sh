@@ -0,0 +1,48 @@
+#!/usr/bin/env bash
+# Builds sha256-repo.bundle, a small test repository that uses the SHA-256
+# object format. Commit dates and identities are fixed so that the resulting
+# commit hashes are always the same.
+#
+# Usage: ./make-sha256-repo.sh (from the test/repos directory)
+set -euo pipefail
+
+outpath="$(pwd)/sha256-repo.bundle"
+workdir="$(mktemp -d)"
+trap 'rm -rf "$workdir"' EXIT
+
+cd "$workdir"
+git init --quiet --object-format=sha256 --initial-branch=main .
+
+export GIT_CONFIG_GLOBAL=/dev/null
+export GIT_CONFIG_SYSTEM=/dev/null
+
+commit_as() {
+    local name="$1" email="$2" date="$3"
+    shift 3
+    GIT_AUTHOR_NAME="$name" GIT_AUTHOR_EMAIL="$email" \
+    GIT_AUTHOR_DATE="$date" \
+    GIT_COMMITTER_NAME="$name" GIT_COMMITTER_EMAIL="$email" \
+    GIT_COMMITTER_DATE="$date" \
+        git commit --quiet "$@"
+}
+
+printf 'Hello, world!\n' > README.md
+git add README.md
+commit_as "Alice" "alice@example.com" "2025-01-01T12:00:00Z" -m "Initial commit"
+git tag root
+
+mkdir src
+printf 'package main\n\nfunc main() {}\n' > src/main.go
+git add src/main.go
+commit_as "Bob" "bob@example.com" "2025-01-02T12:00:00Z" -m "Add main"
+
+printf 'Hello, world!\n\nThis is a test repo.\n' > README.md
+git add README.md
+commit_as "Alice" "alice@example.com" "2025-01-03T12:00:00Z" \
+    -m "Expand README" \
+    -m "Reviewed-by: Bob <bob@example.com>"
+
+git mv src/main.go src/app.go
+commit_as "Bob" "bob@example.com" "2025-01-04T12:00:00Z" -m "Rename main"
+
+git bundle create --quiet "$outpath" --all